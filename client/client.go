@@ -0,0 +1,130 @@
+// Package client builds instrumented clients for the Todo service. Requests
+// are encoded as JSON unless WithProtobuf is set. The request and response
+// types are in the proto package.
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/bakins/twirpotel"
+	"github.com/twitchtv/twirp"
+
+	pb "github.com/bakins/twirp-todo-example/proto"
+)
+
+// DefaultTimeout is used when WithTimeout is not set.
+const DefaultTimeout = time.Second * 30
+
+type config struct {
+	httpClient *http.Client
+	timeout    time.Duration
+	token      string
+	protobuf   bool
+}
+
+type Option interface {
+	apply(*config)
+}
+
+type clientOptionFunc func(*config)
+
+func (f clientOptionFunc) apply(c *config) {
+	f(c)
+}
+
+// WithHTTPClient sets the client used for requests. It is copied, so it is not
+// modified by the other options.
+func WithHTTPClient(client *http.Client) Option {
+	return clientOptionFunc(func(c *config) {
+		c.httpClient = client
+	})
+}
+
+// WithTimeout sets the timeout for each request. Zero means no timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return clientOptionFunc(func(c *config) {
+		c.timeout = timeout
+	})
+}
+
+// WithToken sends token as a bearer token on every request.
+func WithToken(token string) Option {
+	return clientOptionFunc(func(c *config) {
+		c.token = token
+	})
+}
+
+// WithProtobuf uses protobuf rather than JSON encoding, which is smaller and
+// faster to encode, but harder to debug.
+func WithProtobuf() Option {
+	return clientOptionFunc(func(c *config) {
+		c.protobuf = true
+	})
+}
+
+// New returns a Todo service client for the server at baseURL. Trace context
+// is propagated to the server.
+func New(baseURL string, options ...Option) (pb.TodoService, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse base URL %w", err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported base URL scheme %q", u.Scheme)
+	}
+
+	c := config{
+		httpClient: http.DefaultClient,
+		timeout:    DefaultTimeout,
+	}
+
+	for _, o := range options {
+		o.apply(&c)
+	}
+
+	httpClient := *c.httpClient
+	httpClient.Timeout = c.timeout
+
+	if c.token != "" {
+		transport := httpClient.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+
+		httpClient.Transport = &bearerTransport{
+			token: c.token,
+			next:  transport,
+		}
+	}
+
+	interceptors := twirp.WithClientInterceptors(twirpotel.ClientInterceptor())
+
+	if c.protobuf {
+		return pb.NewTodoServiceProtobufClient(baseURL, &httpClient, interceptors), nil
+	}
+
+	return pb.NewTodoServiceJSONClient(baseURL, &httpClient, interceptors), nil
+}
+
+// bearerTransport adds an Authorization header to requests that do not
+// already have one.
+type bearerTransport struct {
+	token string
+	next  http.RoundTripper
+}
+
+func (t *bearerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.Header.Get("Authorization") != "" {
+		return t.next.RoundTrip(r)
+	}
+
+	// a RoundTripper must not modify the request
+	r = r.Clone(r.Context())
+	r.Header.Set("Authorization", "Bearer "+t.token)
+
+	return t.next.RoundTrip(r)
+}
@@ -0,0 +1,56 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/twirp-todo-example/client"
+	pb "github.com/bakins/twirp-todo-example/proto"
+)
+
+type fakeServer struct {
+	pb.TodoService
+}
+
+func (fakeServer) GetTask(ctx context.Context, req *pb.GetTaskRequest) (*pb.GetTaskResponse, error) {
+	return &pb.GetTaskResponse{Task: &pb.Task{Id: req.Id}}, nil
+}
+
+func TestNew(t *testing.T) {
+	ts := pb.NewTodoServiceServer(fakeServer{})
+
+	var authorization, contentType string
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		contentType = r.Header.Get("Content-Type")
+		ts.ServeHTTP(w, r)
+	}))
+	defer svr.Close()
+
+	// JSON is the default
+	encodings := map[string][]client.Option{
+		"application/json":     nil,
+		"application/protobuf": {client.WithProtobuf()},
+	}
+
+	for encoding, options := range encodings {
+		options = append(options, client.WithToken("secret"))
+
+		c, err := client.New(svr.URL, options...)
+		require.NoError(t, err)
+
+		resp, err := c.GetTask(context.Background(), &pb.GetTaskRequest{Id: 7})
+		require.NoError(t, err)
+		require.Equal(t, uint64(7), resp.Task.Id)
+		require.Equal(t, "Bearer secret", authorization)
+		require.Equal(t, encoding, contentType)
+	}
+
+	_, err := client.New("localhost:8080")
+	require.Error(t, err)
+}
@@ -3,87 +3,241 @@ package app
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"os/signal"
 	"syscall"
 
-	"github.com/twitchtv/twirp"
-
-	"github.com/bakins/twirp-reflection/reflection"
-	"github.com/bakins/twirpotel"
+	"github.com/alecthomas/kong"
+	otelapi "go.opentelemetry.io/otel"
+	"go.uber.org/zap"
 
+	"github.com/bakins/twirp-todo-example/internal/auth"
+	"github.com/bakins/twirp-todo-example/internal/concurrency"
 	"github.com/bakins/twirp-todo-example/internal/database"
 	"github.com/bakins/twirp-todo-example/internal/httpserver"
 	"github.com/bakins/twirp-todo-example/internal/logging"
+	"github.com/bakins/twirp-todo-example/internal/metadata"
 	"github.com/bakins/twirp-todo-example/internal/otel"
-	pb "github.com/bakins/twirp-todo-example/internal/proto"
+	"github.com/bakins/twirp-todo-example/internal/service"
+	"github.com/bakins/twirp-todo-example/internal/timeout"
 	"github.com/bakins/twirp-todo-example/internal/todo"
+	"github.com/bakins/twirp-todo-example/schema"
 )
 
 type Config struct {
-	Logging    logging.Config     `kong:"embed,prefix=log."`
-	Httpserver httpserver.Config  `kong:"embed,prefix=http."`
-	Trace      otel.TraceConfig   `kong:"embed,prefix=trace."`
-	Metrics    otel.MetricsConfig `kong:"embed,prefix=metrics."`
-	Database   database.Config    `kong:"embed,prefix=database."`
+	ConfigFile kong.ConfigFlag     `kong:"name=config,help='Path to a JSON configuration file.'"`
+	Logging    logging.Config      `kong:"embed,prefix=log."`
+	Httpserver httpserver.Config   `kong:"embed,prefix=http."`
+	Resource   otel.ResourceConfig `kong:"embed,prefix=resource."`
+	Trace      otel.TraceConfig    `kong:"embed,prefix=trace."`
+	Metrics    otel.MetricsConfig  `kong:"embed,prefix=metrics."`
+	Database   database.Config     `kong:"embed,prefix=database."`
+	Metadata   metadata.Config     `kong:"embed"`
+	Auth       auth.Config         `kong:"embed,prefix=auth."`
+	Timeout    timeout.Config      `kong:"embed,prefix=timeout."`
+	Limits     concurrency.Config  `kong:"embed,prefix=limits."`
 }
 
 // Main should be called from  main.main.
+//
+// Configuration is read from flags, environment variables, and an optional
+// JSON file given by --config, in that order of precedence, before falling
+// back to defaults.
 func Main() int {
 	var cfg Config
 
-	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGQUIT)
+	kong.Parse(
+		&cfg,
+		kong.Name("todo"),
+		kong.Configuration(kong.JSON),
+	)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGQUIT, syscall.SIGTERM)
 	defer cancel()
 
 	return logging.Exit(cfg.Run(ctx))
 }
 
-func (config Config) Run(ctx context.Context) error {
-	logger := config.Logging.Build(ctx)
-	defer logger.Sync()
+func (config Config) Run(ctx context.Context) (err error) {
+	metadata.FromConfig(config.Metadata)
 
-	traceCleanup, err := config.Trace.Build(ctx)
+	logger, level, err := config.Logging.Build(ctx)
 	if err != nil {
 		return err
 	}
 
+	defer func() {
+		if syncErr := logging.Sync(logger); syncErr != nil && err == nil {
+			err = fmt.Errorf("failed to sync logger %w", syncErr)
+		}
+	}()
+
+	ctx = logging.ToContext(ctx, logger)
+
+	// export failures would otherwise only be printed to stderr
+	otelapi.SetErrorHandler(logging.OTelErrorHandler(logger))
+
+	// the project id is needed to link logs to traces
+	if err := metadata.FromMetadataServer(ctx); err != nil {
+		logger.Debug("GCP metadata server is not available", zap.Error(err))
+	}
+
+	config.LogStartup(logger)
+
+	// traces and metrics share a resource, so they are described the same
+	res, err := config.Resource.Build(ctx)
+	if err != nil {
+		return logging.Fatal(ctx, "failed to configure telemetry", zap.Error(err))
+	}
+
+	traceCleanup, err := config.Trace.Build(ctx, res)
+	if err != nil {
+		return logging.Fatal(ctx, "failed to configure tracing", zap.Error(err))
+	}
+
 	defer traceCleanup()
 
-	metricsCleanup, err := config.Metrics.Build(ctx)
+	metricsHandler, metricsCleanup, err := config.Metrics.Build(ctx, res)
 	if err != nil {
-		return err
+		return logging.Fatal(ctx, "failed to configure metrics", zap.Error(err))
 	}
 
 	defer metricsCleanup()
 
+	if config.Database.SchemaFS == nil {
+		config.Database.SchemaFS = schema.FS
+		if config.Database.Driver == database.Postgres {
+			config.Database.SchemaFS = schema.Postgres()
+		}
+	}
+
 	db, err := config.Database.Build(ctx)
 	if err != nil {
-		return err
+		return logging.Fatal(ctx, "failed to open database", zap.Error(err))
 	}
 
 	defer db.Close()
 
+	// the schema may be migrated by another process, such as when read-only,
+	// so readiness waits for the latest version.
+	schemaVersion, err := config.Database.SchemaVersion()
+	if err != nil {
+		return logging.Fatal(ctx, "failed to read schema version", zap.Error(err))
+	}
+
+	if applied, dirty, err := config.Database.MigrationVersion(ctx, db); err != nil {
+		logger.Warn("failed to read migration version", zap.Error(err))
+	} else {
+		logger.Info("database schema",
+			zap.Uint("version", applied),
+			zap.Uint("expected", schemaVersion),
+			zap.Bool("dirty", dirty),
+		)
+	}
+
 	svr, err := config.Httpserver.Build(ctx)
+	if err != nil {
+		return logging.Fatal(ctx, "failed to create HTTP server", zap.Error(err))
+	}
+
+	switch {
+	case !svr.AdminEnabled():
+		logger.Warn("admin endpoints, such as metrics, are disabled as there is no admin address")
+	case config.Httpserver.AdminAddress == "":
+		logger.Warn("admin endpoints are served on the public address")
+	}
+
+	// the verifier is shared so JSON Web Keys are only fetched once
+	verifier := config.Auth.Verifier(ctx)
+
+	// authenticated returns handler, requiring a bearer token when
+	// authentication is enabled.
+	authenticated := func(handler http.Handler) http.Handler {
+		if verifier == nil {
+			return handler
+		}
+
+		return auth.Require(verifier)(handler)
+	}
+
+	// handleAdminOnly adds an admin endpoint that changes the server. When it
+	// is served on the public address it requires an admin token, so it is
+	// skipped if authentication is disabled.
+	handleAdminOnly := func(pattern string, handler http.Handler) {
+		if config.Httpserver.AdminAddress != "" {
+			svr.HandleAdmin(pattern, handler)
+			return
+		}
+
+		if verifier == nil {
+			if svr.AdminEnabled() {
+				logger.Warn("admin endpoint is disabled on the public address without authentication", zap.String("path", pattern))
+			}
+
+			return
+		}
+
+		svr.HandleAdmin(pattern, auth.RequireAdmin(verifier)(handler))
+	}
+
+	handleAdminOnly("/loglevel", level)
+
+	if metricsHandler != nil {
+		svr.HandleAdmin("/metrics", metricsHandler)
+	}
+
+	if config.Database.BackupDirectory != "" {
+		if config.Database.Driver == database.Postgres {
+			return logging.Fatal(ctx, "backups are only supported for sqlite")
+		}
+
+		handleAdminOnly("/backup", database.BackupHandler(db, config.Database.BackupDirectory))
+	}
+
+	s, err := todo.New(db,
+		todo.WithDriver(config.Database.Driver),
+		todo.WithSlowQueryThreshold(config.Database.SlowQueryThreshold),
+		todo.WithTablePrefix(config.Database.TablePrefix),
+		todo.WithLoadShedding(config.Database.LoadShedThreshold),
+	)
 	if err != nil {
 		return err
 	}
 
-	s, err := todo.New(db)
+	defer s.Close()
+
+	svr.HandleAdmin("/export", authenticated(s.ExportHandler()))
+	svr.HandleAdmin("/import", authenticated(httpserver.MaxBytes(config.Httpserver.MaxRequestBytes)(s.ImportHandler())))
+
+	serviceOptions := []service.Option{
+		service.WithTimeout(config.Timeout.Build(ctx)),
+		service.WithLimits(config.Limits.Build(ctx)),
+	}
+
+	if verifier != nil {
+		svr.AddMiddleware(auth.Middleware)
+		serviceOptions = append(serviceOptions, service.WithAuth(verifier, auth.WithSkipMethods(config.Auth.SkipMethods...)))
+	}
+
+	ts, err := service.New(s, serviceOptions...)
 	if err != nil {
 		return err
 	}
 
-	ts := pb.NewTodoServiceServer(
-		s,
-		twirp.WithServerInterceptors(
-			twirpotel.ServerInterceptor(),
-		),
-	)
+	svr.RegisterServices(ts)
+	svr.HandleStream("/watch", authenticated(s.WatchHandler()))
+	svr.RegisterOnShutdown(s.CloseWatches)
+
+	svr.AddHealthCheck("database", func(ctx context.Context) error {
+		return config.Database.Ping(ctx, db)
+	})
 
-	svr.Handle(ts.PathPrefix(), ts)
+	svr.AddHealthCheck("latency", s.CheckLatency)
 
-	r := reflection.NewServer()
-	r.RegisterService(ts)
-	svr.Handle(r.PathPrefix(), r)
+	svr.AddHealthCheck("migrations", func(ctx context.Context) error {
+		return config.Database.CheckMigrations(ctx, db, schemaVersion)
+	})
 
 	return svr.Run(ctx)
 }
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"net/url"
+
+	"go.uber.org/zap"
+
+	"github.com/bakins/twirp-todo-example/internal/metadata"
+)
+
+// LogStartup logs the resolved configuration. Secrets, such as tokens and
+// passwords, are not logged, only whether they are set.
+func (config Config) LogStartup(logger *zap.Logger) {
+	logger.Info("starting",
+		zap.String("service", metadata.Service()),
+		zap.String("version", metadata.Version()),
+		zap.String("project", metadata.Project()),
+		zap.String("region", metadata.Region()),
+		zap.String("log.level", config.Logging.Level),
+		zap.String("http.address", config.Httpserver.Address),
+		zap.String("http.admin_address", config.Httpserver.AdminAddress),
+		zap.Bool("http.public_admin", config.Httpserver.PublicAdmin),
+		zap.Bool("http.tls", config.Httpserver.TLSCertFile != ""),
+		zap.Bool("http.basic_auth", config.Httpserver.BasicAuthUsername != ""),
+		zap.String("database.driver", config.Database.Driver),
+		zap.String("database.filename", config.Database.Filename),
+		zap.String("database.url", redactURL(config.Database.URL)),
+		zap.String("trace.exporter", config.Trace.Exporter),
+		zap.String("trace.endpoint", config.Trace.Endpoint),
+		zap.String("metrics.endpoint", config.Metrics.Endpoint),
+		zap.Bool("metrics.prometheus", config.Metrics.Prometheus),
+		zap.Duration("metrics.collect_period", config.Metrics.CollectPeriod),
+		zap.String("metrics.aggregation", config.Metrics.Aggregation),
+		zap.Bool("auth", config.Auth.Token != "" || config.Auth.AdminToken != "" || config.Auth.JWKSURL != ""),
+		zap.String("auth.jwks_url", config.Auth.JWKSURL),
+	)
+}
+
+// redactURL masks any password in u.
+func redactURL(u string) string {
+	if u == "" {
+		return ""
+	}
+
+	// a connection string that is not a URL may still contain a password
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.Scheme == "" {
+		return "***"
+	}
+
+	return parsed.Redacted()
+}
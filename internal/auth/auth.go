@@ -0,0 +1,287 @@
+// Package auth provides bearer token authentication for Twirp services.
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/twitchtv/twirp"
+
+	"github.com/bakins/twirp-todo-example/internal/rpcmethod"
+)
+
+// Identity describes an authenticated caller.
+type Identity struct {
+	Subject string
+	// Admin callers may use administrative options, such as reading deleted
+	// tasks.
+	Admin bool
+}
+
+// Verifier validates a bearer token and returns the identity of the caller.
+type Verifier interface {
+	Verify(ctx context.Context, token string) (*Identity, error)
+}
+
+// VerifierFunc adapts a function to a Verifier.
+type VerifierFunc func(ctx context.Context, token string) (*Identity, error)
+
+func (f VerifierFunc) Verify(ctx context.Context, token string) (*Identity, error) {
+	return f(ctx, token)
+}
+
+// ErrInvalidToken should be returned by a Verifier when a token is not valid.
+var ErrInvalidToken = errors.New("invalid token")
+
+// StaticToken returns a Verifier that accepts a single shared secret. Callers
+// using it are identified as subject.
+func StaticToken(token string, subject string) Verifier {
+	return VerifierFunc(func(ctx context.Context, t string) (*Identity, error) {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) != 1 {
+			return nil, ErrInvalidToken
+		}
+
+		return &Identity{Subject: subject}, nil
+	})
+}
+
+// IsAdmin returns true if the caller is an authenticated admin.
+func IsAdmin(ctx context.Context) bool {
+	identity, ok := IdentityFromContext(ctx)
+
+	return ok && identity.Admin
+}
+
+// FirstOf returns a Verifier that tries each of verifiers in turn, returning
+// the first identity found.
+func FirstOf(verifiers ...Verifier) Verifier {
+	return VerifierFunc(func(ctx context.Context, token string) (*Identity, error) {
+		for _, v := range verifiers {
+			identity, err := v.Verify(ctx, token)
+			if err == nil {
+				return identity, nil
+			}
+		}
+
+		return nil, ErrInvalidToken
+	})
+}
+
+// AsAdmin returns a Verifier that marks the identities returned by verifier
+// as admins.
+func AsAdmin(verifier Verifier) Verifier {
+	return VerifierFunc(func(ctx context.Context, token string) (*Identity, error) {
+		identity, err := verifier.Verify(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+
+		admin := *identity
+		admin.Admin = true
+
+		return &admin, nil
+	})
+}
+
+type Config struct {
+	// Token is a shared secret clients must send as a bearer token. When
+	// both tokens are empty, authentication is disabled.
+	Token string `kong:"env=AUTH_TOKEN"`
+	// AdminToken is a shared secret for admin callers. They own the same
+	// tasks as callers using Token.
+	AdminToken string `kong:"env=AUTH_ADMIN_TOKEN"`
+	// JWKSURL enables JSON Web Tokens signed by the keys at this URL. The
+	// subject of the token owns the caller's tasks.
+	JWKSURL string `kong:"name=jwks-url,env=AUTH_JWKS_URL"`
+	// JWTIssuer and JWTAudience, if set, must match the token's claims.
+	JWTIssuer   string `kong:"name=jwt-issuer,env=AUTH_JWT_ISSUER"`
+	JWTAudience string `kong:"name=jwt-audience,env=AUTH_JWT_AUDIENCE"`
+	// SkipMethods do not require authentication. Methods are named
+	// package.Service/Method.
+	SkipMethods []string `kong:""`
+}
+
+// Build returns an interceptor, or nil if authentication is disabled.
+func (c Config) Build(ctx context.Context) twirp.Interceptor {
+	verifier := c.Verifier(ctx)
+	if verifier == nil {
+		return nil
+	}
+
+	return Interceptor(verifier, WithSkipMethods(c.SkipMethods...))
+}
+
+// Verifier returns the verifier for the configured tokens, or nil if
+// authentication is disabled. It may be shared by Interceptor and Require.
+func (c Config) Verifier(ctx context.Context) Verifier {
+	var verifiers []Verifier
+
+	if c.Token != "" {
+		verifiers = append(verifiers, StaticToken(c.Token, "static"))
+	}
+
+	if c.AdminToken != "" {
+		verifiers = append(verifiers, AsAdmin(StaticToken(c.AdminToken, "static")))
+	}
+
+	if c.JWKSURL != "" {
+		verifiers = append(verifiers, NewJWTVerifier(c.JWKSURL,
+			WithIssuer(c.JWTIssuer),
+			WithAudience(c.JWTAudience),
+		))
+	}
+
+	if len(verifiers) == 0 {
+		return nil
+	}
+
+	return FirstOf(verifiers...)
+}
+
+type interceptorConfig struct {
+	skip map[string]bool
+}
+
+type Option interface {
+	apply(*interceptorConfig)
+}
+
+type interceptorOptionFunc func(*interceptorConfig)
+
+func (f interceptorOptionFunc) apply(c *interceptorConfig) {
+	f(c)
+}
+
+// WithSkipMethods allows the given methods to be called without
+// authentication. Methods are named package.Service/Method.
+func WithSkipMethods(methods ...string) Option {
+	return interceptorOptionFunc(func(c *interceptorConfig) {
+		for _, m := range methods {
+			c.skip[m] = true
+		}
+	})
+}
+
+// Interceptor returns a twirp interceptor that requires a valid bearer token
+// on every call. The identity of the caller is added to the context. Requests
+// must pass through Middleware so the Authorization header is available.
+func Interceptor(verifier Verifier, options ...Option) twirp.Interceptor {
+	cfg := interceptorConfig{
+		skip: map[string]bool{},
+	}
+
+	for _, o := range options {
+		o.apply(&cfg)
+	}
+
+	return func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if cfg.skip[rpcmethod.Name(ctx)] {
+				return next(ctx, req)
+			}
+
+			token, ok := bearerToken(ctx)
+			if !ok {
+				return nil, twirp.NewError(twirp.Unauthenticated, "missing bearer token")
+			}
+
+			identity, err := verifier.Verify(ctx, token)
+			if err != nil {
+				return nil, twirp.NewError(twirp.Unauthenticated, "invalid bearer token")
+			}
+
+			return next(WithIdentity(ctx, identity), req)
+		}
+	}
+}
+
+type authorizationMarker struct{}
+
+type identityMarker struct{}
+
+var (
+	authorizationMarkerKey = &authorizationMarker{}
+	identityMarkerKey      = &identityMarker{}
+)
+
+// Middleware adds the Authorization header to the context of each request
+// for use by Interceptor.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h := r.Header.Get("Authorization"); h != "" {
+			r = r.WithContext(context.WithValue(r.Context(), authorizationMarkerKey, h))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Require is middleware for plain HTTP handlers that requires a valid bearer
+// token, as Interceptor does for Twirp methods. The identity of the caller is
+// added to the request context. Other requests receive a 401.
+func Require(verifier Verifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := parseBearer(r.Header.Get("Authorization"))
+			if !ok {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, "missing bearer token", http.StatusUnauthorized)
+				return
+			}
+
+			identity, err := verifier.Verify(r.Context(), token)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				http.Error(w, "invalid bearer token", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), identity)))
+		})
+	}
+}
+
+// RequireAdmin is Require for handlers only admins may use. Callers that are
+// not admins receive a 403.
+func RequireAdmin(verifier Verifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return Require(verifier)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !IsAdmin(r.Context()) {
+				http.Error(w, "admin token required", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}))
+	}
+}
+
+func bearerToken(ctx context.Context) (string, bool) {
+	h, _ := ctx.Value(authorizationMarkerKey).(string)
+
+	return parseBearer(h)
+}
+
+func parseBearer(h string) (string, bool) {
+	const prefix = "bearer "
+	if len(h) <= len(prefix) || !strings.EqualFold(h[:len(prefix)], prefix) {
+		return "", false
+	}
+
+	return strings.TrimSpace(h[len(prefix):]), true
+}
+
+// WithIdentity adds the identity of the caller to the context.
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityMarkerKey, identity)
+}
+
+// IdentityFromContext returns the identity of the caller, if authenticated.
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityMarkerKey).(*Identity)
+
+	return identity, ok && identity != nil
+}
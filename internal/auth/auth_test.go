@@ -0,0 +1,174 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+
+	"github.com/bakins/twirp-todo-example/internal/auth"
+	"github.com/bakins/twirp-todo-example/internal/rpcmethod"
+)
+
+// contextWithHeader runs auth.Middleware and returns the resulting context.
+func contextWithHeader(t *testing.T, method string, authorization string) context.Context {
+	var ctx context.Context
+
+	handler := auth.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	if authorization != "" {
+		r.Header.Set("Authorization", authorization)
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	return rpcmethod.WithName(ctx, "bakins.todo.v1.TodoService/"+method)
+}
+
+func TestInterceptor(t *testing.T) {
+	interceptor := auth.Interceptor(
+		auth.StaticToken("secret", "tester"),
+		auth.WithSkipMethods("bakins.todo.v1.TodoService/ListTasks"),
+	)
+
+	method := interceptor(func(ctx context.Context, req interface{}) (interface{}, error) {
+		identity, ok := auth.IdentityFromContext(ctx)
+		if !ok {
+			return "anonymous", nil
+		}
+
+		return identity.Subject, nil
+	})
+
+	tests := map[string]struct {
+		method        string
+		authorization string
+		expected      string
+		code          twirp.ErrorCode
+	}{
+		"valid": {
+			method:        "CreateTask",
+			authorization: "Bearer secret",
+			expected:      "tester",
+		},
+		"missing": {
+			method: "CreateTask",
+			code:   twirp.Unauthenticated,
+		},
+		"invalid": {
+			method:        "CreateTask",
+			authorization: "Bearer wrong",
+			code:          twirp.Unauthenticated,
+		},
+		"skipped": {
+			method:   "ListTasks",
+			expected: "anonymous",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp, err := method(contextWithHeader(t, tt.method, tt.authorization), nil)
+
+			if tt.code != "" {
+				var twerr twirp.Error
+				require.True(t, errors.As(err, &twerr))
+				require.Equal(t, tt.code, twerr.Code())
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, resp)
+		})
+	}
+}
+
+func TestAdmin(t *testing.T) {
+	verifier := auth.FirstOf(
+		auth.StaticToken("secret", "tester"),
+		auth.AsAdmin(auth.StaticToken("admin-secret", "tester")),
+	)
+
+	ctx := context.Background()
+
+	identity, err := verifier.Verify(ctx, "secret")
+	require.NoError(t, err)
+	require.False(t, auth.IsAdmin(auth.WithIdentity(ctx, identity)))
+
+	identity, err = verifier.Verify(ctx, "admin-secret")
+	require.NoError(t, err)
+	require.Equal(t, "tester", identity.Subject)
+	require.True(t, auth.IsAdmin(auth.WithIdentity(ctx, identity)))
+
+	_, err = verifier.Verify(ctx, "wrong")
+	require.ErrorIs(t, err, auth.ErrInvalidToken)
+
+	require.False(t, auth.IsAdmin(ctx))
+}
+
+func TestRequire(t *testing.T) {
+	handler := auth.Require(auth.StaticToken("secret", "user"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, ok := auth.IdentityFromContext(r.Context())
+		require.True(t, ok)
+		_, _ = w.Write([]byte(identity.Subject))
+	}))
+
+	tests := map[string]int{
+		"":              http.StatusUnauthorized,
+		"Bearer wrong":  http.StatusUnauthorized,
+		"Bearer secret": http.StatusOK,
+	}
+
+	for authorization, expected := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if authorization != "" {
+			r.Header.Set("Authorization", authorization)
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		require.Equal(t, expected, w.Code, authorization)
+
+		if expected == http.StatusOK {
+			require.Equal(t, "user", w.Body.String())
+		} else {
+			require.Contains(t, w.Header().Get("WWW-Authenticate"), "Bearer")
+		}
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	handler := auth.RequireAdmin(auth.FirstOf(
+		auth.StaticToken("user", "user"),
+		auth.AsAdmin(auth.StaticToken("admin", "admin")),
+	))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := map[string]int{
+		"":             http.StatusUnauthorized,
+		"Bearer wrong": http.StatusUnauthorized,
+		"Bearer user":  http.StatusForbidden,
+		"Bearer admin": http.StatusOK,
+	}
+
+	for authorization, expected := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		if authorization != "" {
+			r.Header.Set("Authorization", authorization)
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		require.Equal(t, expected, w.Code, authorization)
+	}
+}
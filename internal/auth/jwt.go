@@ -0,0 +1,413 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// DefaultJWKSRefresh is how often keys are fetched by a JWTVerifier.
+	DefaultJWKSRefresh = time.Hour
+	// jwksMinRefresh limits how often keys are fetched, whether or not the
+	// last attempt failed, so invalid tokens or an unavailable key server do
+	// not cause a fetch for every request.
+	jwksMinRefresh = time.Minute
+	// jwksTimeout limits fetching keys with the default client.
+	jwksTimeout = time.Second * 10
+	// clockSkew is allowed when checking expiry and not before times.
+	clockSkew = time.Minute
+)
+
+// JWTVerifier verifies JSON Web Tokens signed with RS256 or ES256, using keys
+// from a JWKS URL. Keys are cached and refreshed periodically, or sooner if a
+// token uses an unknown key. The subject of the token identifies the caller.
+type JWTVerifier struct {
+	url      string
+	client   *http.Client
+	refresh  time.Duration
+	issuer   string
+	audience string
+	now      func() time.Time
+
+	// group ensures concurrent requests wait for a single fetch, which is
+	// made without holding lock.
+	group singleflight.Group
+
+	lock      sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetched   time.Time
+	attempted time.Time
+	// err is from the last attempt to fetch keys, if it failed.
+	err error
+}
+
+var _ Verifier = &JWTVerifier{}
+
+type JWTOption interface {
+	apply(*JWTVerifier)
+}
+
+type jwtOptionFunc func(*JWTVerifier)
+
+func (f jwtOptionFunc) apply(v *JWTVerifier) {
+	f(v)
+}
+
+// WithIssuer requires tokens to have the iss claim.
+func WithIssuer(issuer string) JWTOption {
+	return jwtOptionFunc(func(v *JWTVerifier) {
+		v.issuer = issuer
+	})
+}
+
+// WithAudience requires tokens to include audience in the aud claim.
+func WithAudience(audience string) JWTOption {
+	return jwtOptionFunc(func(v *JWTVerifier) {
+		v.audience = audience
+	})
+}
+
+// WithJWKSRefresh sets how often keys are fetched. The default is
+// DefaultJWKSRefresh.
+func WithJWKSRefresh(interval time.Duration) JWTOption {
+	return jwtOptionFunc(func(v *JWTVerifier) {
+		if interval > 0 {
+			v.refresh = interval
+		}
+	})
+}
+
+// WithJWKSClient sets the client used to fetch keys. The default client times
+// out after 10 seconds.
+func WithJWKSClient(client *http.Client) JWTOption {
+	return jwtOptionFunc(func(v *JWTVerifier) {
+		v.client = client
+	})
+}
+
+// NewJWTVerifier creates a verifier using the keys at url. Keys are fetched
+// on first use.
+func NewJWTVerifier(url string, options ...JWTOption) *JWTVerifier {
+	v := JWTVerifier{
+		url:     url,
+		client:  &http.Client{Timeout: jwksTimeout},
+		refresh: DefaultJWKSRefresh,
+		now:     time.Now,
+	}
+
+	for _, o := range options {
+		o.apply(&v)
+	}
+
+	return &v
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Subject   string          `json:"sub"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *int64          `json:"exp"`
+	NotBefore *int64          `json:"nbf"`
+}
+
+// Verify returns ErrInvalidToken if the token is malformed, is not signed by
+// a known key, has expired, or does not match the issuer or audience. Other
+// errors are from fetching keys.
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (*Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	key, err := v.key(ctx, header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+
+	if !verifySignature(header.Algorithm, key, digest[:], signature) {
+		return nil, ErrInvalidToken
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if err := v.checkClaims(&claims); err != nil {
+		return nil, err
+	}
+
+	return &Identity{Subject: claims.Subject}, nil
+}
+
+func (v *JWTVerifier) checkClaims(claims *jwtClaims) error {
+	now := v.now()
+
+	switch {
+	case claims.Subject == "":
+		return ErrInvalidToken
+	case claims.ExpiresAt == nil || now.After(time.Unix(*claims.ExpiresAt, 0).Add(clockSkew)):
+		return ErrInvalidToken
+	case claims.NotBefore != nil && now.Add(clockSkew).Before(time.Unix(*claims.NotBefore, 0)):
+		return ErrInvalidToken
+	case v.issuer != "" && claims.Issuer != v.issuer:
+		return ErrInvalidToken
+	case v.audience != "" && !hasAudience(claims.Audience, v.audience):
+		return ErrInvalidToken
+	}
+
+	return nil
+}
+
+// hasAudience handles aud being either a string or a list of strings.
+func hasAudience(raw json.RawMessage, audience string) bool {
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return single == audience
+	}
+
+	var list []string
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return false
+	}
+
+	for _, a := range list {
+		if a == audience {
+			return true
+		}
+	}
+
+	return false
+}
+
+func verifySignature(algorithm string, key crypto.PublicKey, digest []byte, signature []byte) bool {
+	switch algorithm {
+	case "RS256":
+		pub, ok := key.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest, signature) == nil
+	case "ES256":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(signature) != 64 {
+			return false
+		}
+
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+
+		return ecdsa.Verify(pub, digest, r, s)
+	default:
+		// notably "none" and HMAC, which would let a public key be used as a
+		// shared secret.
+		return false
+	}
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// key returns the key with id, fetching keys if they are stale or id is
+// unknown.
+func (v *JWTVerifier) key(ctx context.Context, id string) (crypto.PublicKey, error) {
+	v.lock.Lock()
+	now := v.now()
+	key, ok := v.keys[id]
+	stale := now.Sub(v.fetched) >= v.refresh
+	due := now.Sub(v.attempted) >= jwksMinRefresh
+	v.lock.Unlock()
+
+	// refreshKeys is rate limited, and waits for a fetch in progress, so it
+	// is always used for an unknown id.
+	if (due && stale) || !ok {
+		keys, err := v.refreshKeys(ctx)
+		if err != nil {
+			// keep using cached keys while the key server is unavailable
+			if !ok {
+				return nil, err
+			}
+
+			return key, nil
+		}
+
+		key, ok = keys[id]
+	}
+
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	return key, nil
+}
+
+// refreshKeys fetches keys, sharing the result with concurrent callers. The
+// attempt is recorded first so failures are not retried sooner than
+// jwksMinRefresh.
+func (v *JWTVerifier) refreshKeys(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	result, err, _ := v.group.Do("", func() (interface{}, error) {
+		v.lock.Lock()
+		now := v.now()
+
+		// another caller may have fetched since key checked
+		if now.Sub(v.attempted) < jwksMinRefresh {
+			keys, err := v.keys, v.err
+			v.lock.Unlock()
+
+			return keys, err
+		}
+
+		v.attempted = now
+		v.lock.Unlock()
+
+		keys, err := v.fetch(ctx)
+
+		v.lock.Lock()
+		defer v.lock.Unlock()
+
+		v.err = err
+
+		if err != nil {
+			return nil, err
+		}
+
+		v.keys = keys
+		v.fetched = now
+
+		return keys, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(map[string]crypto.PublicKey), nil
+}
+
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+func (v *JWTVerifier) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWKS request %w", err)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: unexpected status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		// unsupported keys are skipped so one does not break the others
+		if key, err := k.publicKey(); err == nil {
+			keys[k.KeyID] = key
+		}
+	}
+
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		if k.Curve != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	default:
+		return nil, errors.New("unsupported key type")
+	}
+}
@@ -0,0 +1,188 @@
+package auth_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/twirp-todo-example/internal/auth"
+)
+
+func signJWT(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	encode := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	signed := encode(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid}) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(signed))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestJWTVerifier(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var fetches int32
+
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	defer jwks.Close()
+
+	verifier := auth.NewJWTVerifier(jwks.URL,
+		auth.WithIssuer("https://issuer.example.com"),
+		auth.WithAudience("todo"),
+	)
+
+	valid := map[string]interface{}{
+		"sub": "user-1",
+		"iss": "https://issuer.example.com",
+		"aud": []string{"other", "todo"},
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+
+	with := func(key string, value interface{}) map[string]interface{} {
+		claims := map[string]interface{}{}
+		for k, v := range valid {
+			claims[k] = v
+		}
+
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+
+		return claims
+	}
+
+	ctx := context.Background()
+
+	identity, err := verifier.Verify(ctx, signJWT(t, key, "test", valid))
+	require.NoError(t, err)
+	require.Equal(t, "user-1", identity.Subject)
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	invalid := map[string]string{
+		"expired":        signJWT(t, key, "test", with("exp", time.Now().Add(-time.Hour).Unix())),
+		"no expiry":      signJWT(t, key, "test", with("exp", nil)),
+		"wrong issuer":   signJWT(t, key, "test", with("iss", "https://other.example.com")),
+		"wrong audience": signJWT(t, key, "test", with("aud", "other")),
+		"wrong key":      signJWT(t, other, "test", valid),
+		"unknown key":    signJWT(t, key, "unknown", valid),
+		"malformed":      "not-a-jwt",
+	}
+
+	for name, token := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := verifier.Verify(ctx, token)
+			require.ErrorIs(t, err, auth.ErrInvalidToken)
+		})
+	}
+
+	// keys are cached, and an unknown key does not cause an immediate
+	// refetch.
+	require.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+}
+
+func TestJWTVerifierFetch(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var (
+		fetches   int32
+		available int32
+	)
+
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+
+		if atomic.LoadInt32(&available) == 0 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		// slow enough that concurrent requests wait for this fetch
+		time.Sleep(time.Millisecond * 100)
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	defer jwks.Close()
+
+	token := signJWT(t, key, "test", map[string]interface{}{
+		"sub": "user-1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	ctx := context.Background()
+
+	// a failed fetch is not retried for every request
+	failing := auth.NewJWTVerifier(jwks.URL)
+
+	for i := 0; i < 3; i++ {
+		_, err := failing.Verify(ctx, token)
+		require.Error(t, err)
+		require.NotErrorIs(t, err, auth.ErrInvalidToken)
+	}
+
+	require.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+
+	// concurrent requests share a single fetch
+	atomic.StoreInt32(&available, 1)
+	atomic.StoreInt32(&fetches, 0)
+
+	verifier := auth.NewJWTVerifier(jwks.URL)
+
+	errs := make(chan error, 10)
+
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			_, err := verifier.Verify(ctx, token)
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < cap(errs); i++ {
+		require.NoError(t, <-errs)
+	}
+
+	require.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+}
@@ -0,0 +1,110 @@
+// Package concurrency limits how many Twirp methods run at once.
+package concurrency
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/twitchtv/twirp"
+
+	"github.com/bakins/twirp-todo-example/internal/rpcmethod"
+)
+
+const (
+	read  = "read"
+	write = "write"
+)
+
+// methods classifies the TodoService methods, named package.Service/Method,
+// by whether they write to the database. Methods that are not listed are
+// limited as writes, so a new method is never given more concurrency than it
+// should have.
+var methods = map[string]string{
+	"bakins.todo.v1.TodoService/ListTasks":        read,
+	"bakins.todo.v1.TodoService/GetTask":          read,
+	"bakins.todo.v1.TodoService/BatchGetTasks":    read,
+	"bakins.todo.v1.TodoService/ListAuditEntries": read,
+	"bakins.todo.v1.TodoService/CreateTask":       write,
+	"bakins.todo.v1.TodoService/DeleteTask":       write,
+	"bakins.todo.v1.TodoService/RestoreTask":      write,
+	"bakins.todo.v1.TodoService/ArchiveTask":      write,
+	"bakins.todo.v1.TodoService/UnarchiveTask":    write,
+	"bakins.todo.v1.TodoService/ReorderTask":      write,
+}
+
+type Config struct {
+	// Reads is the most read methods that may run at once. Zero means no
+	// limit.
+	Reads int `kong:"default=0"`
+	// Writes is the most write methods that may run at once. SQLite
+	// serializes writes, so a small limit keeps them from holding every
+	// connection. Zero means no limit.
+	Writes int `kong:"default=0"`
+}
+
+// Build returns an interceptor, or nil if there are no limits.
+func (c Config) Build(ctx context.Context) twirp.Interceptor {
+	if c.Reads <= 0 && c.Writes <= 0 {
+		return nil
+	}
+
+	return Interceptor(c.Reads, c.Writes)
+}
+
+// semaphore is nil when there is no limit.
+type semaphore chan struct{}
+
+func newSemaphore(limit int) semaphore {
+	if limit <= 0 {
+		return nil
+	}
+
+	return make(semaphore, limit)
+}
+
+func (s semaphore) tryAcquire() bool {
+	if s == nil {
+		return true
+	}
+
+	select {
+	case s <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s semaphore) release() {
+	if s != nil {
+		<-s
+	}
+}
+
+// Interceptor returns a twirp interceptor that limits the number of read and
+// write methods running at once, separately, so a flood of writes does not
+// starve reads. Zero means no limit. Calls over the limit fail immediately
+// with a resource_exhausted error. It should run after authentication, so
+// unauthenticated calls do not take up slots.
+func Interceptor(reads int, writes int) twirp.Interceptor {
+	readSem := newSemaphore(reads)
+	writeSem := newSemaphore(writes)
+
+	return func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			sem, limit, kind := writeSem, writes, write
+			if methods[rpcmethod.Name(ctx)] == read {
+				sem, limit, kind = readSem, reads, read
+			}
+
+			if !sem.tryAcquire() {
+				return nil, twirp.NewError(twirp.ResourceExhausted, "too many concurrent "+kind+" requests").
+					WithMeta("limit", strconv.Itoa(limit))
+			}
+
+			defer sem.release()
+
+			return next(ctx, req)
+		}
+	}
+}
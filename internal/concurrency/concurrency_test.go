@@ -0,0 +1,64 @@
+package concurrency_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+
+	"github.com/bakins/twirp-todo-example/internal/concurrency"
+	"github.com/bakins/twirp-todo-example/internal/rpcmethod"
+)
+
+func TestInterceptor(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	method := concurrency.Interceptor(0, 1)(func(ctx context.Context, req interface{}) (interface{}, error) {
+		if req == "block" {
+			close(started)
+			<-release
+		}
+
+		return "ok", nil
+	})
+
+	ctx := context.Background()
+	create := rpcmethod.WithName(ctx, "bakins.todo.v1.TodoService/CreateTask")
+	list := rpcmethod.WithName(ctx, "bakins.todo.v1.TodoService/ListTasks")
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := method(create, "block")
+		done <- err
+	}()
+
+	<-started
+
+	// the write limit is reached
+	_, err := method(create, nil)
+
+	var twerr twirp.Error
+	require.True(t, errors.As(err, &twerr))
+	require.Equal(t, twirp.ResourceExhausted, twerr.Code())
+	require.Equal(t, "1", twerr.Meta("limit"))
+
+	// reads are not limited
+	resp, err := method(list, nil)
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp)
+
+	// methods are matched with their service, and unknown methods are
+	// limited as writes
+	_, err = method(rpcmethod.WithName(ctx, "other.v1.OtherService/ListTasks"), nil)
+	require.True(t, errors.As(err, &twerr))
+	require.Equal(t, twirp.ResourceExhausted, twerr.Code())
+
+	close(release)
+	require.NoError(t, <-done)
+
+	_, err = method(create, nil)
+	require.NoError(t, err)
+}
@@ -0,0 +1,22 @@
+package concurrency
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/bakins/twirp-todo-example/proto"
+)
+
+func TestMethodsClassified(t *testing.T) {
+	service := pb.File_proto_todo_proto.Services().ByName("TodoService")
+	require.NotNil(t, service)
+
+	list := service.Methods()
+	require.Equal(t, list.Len(), len(methods))
+
+	for i := 0; i < list.Len(); i++ {
+		name := string(service.FullName()) + "/" + string(list.Get(i).Name())
+		require.Contains(t, methods, name, "method is not classified as a read or write")
+	}
+}
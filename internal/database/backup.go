@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bakins/twirp-todo-example/internal/logging"
+)
+
+// Backup writes a consistent copy of a sqlite database to path, which must not
+// exist. VACUUM INTO only holds a read transaction, so in WAL mode writers are
+// not blocked while the copy is made. The size of the copy is returned.
+func Backup(ctx context.Context, db *sql.DB, path string) (int64, error) {
+	if _, err := db.ExecContext(ctx, "vacuum into ?", path); err != nil {
+		return 0, fmt.Errorf("failed to backup database to %q %w", path, err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat backup %w", err)
+	}
+
+	return info.Size(), nil
+}
+
+// BackupResult is returned by BackupHandler.
+type BackupResult struct {
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+	Duration string `json:"duration"`
+}
+
+// BackupHandler backs up the database into dir when it receives a POST. Each
+// backup has a unique, timestamped name. The handler does not check the
+// caller, so it must be served on the admin listener or wrapped with
+// auth.RequireAdmin. Failures are logged rather than returned, so paths on the
+// server are not exposed.
+func BackupHandler(db *sql.DB, dir string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		start := time.Now()
+		path := filepath.Join(dir, "backup-"+start.UTC().Format("20060102T150405.000000000")+".db")
+
+		size, err := Backup(r.Context(), db, path)
+		if err != nil {
+			logging.Error(r.Context(), "failed to backup database", zap.Error(err))
+			http.Error(w, "failed to backup database", http.StatusInternalServerError)
+			return
+		}
+
+		result := BackupResult{
+			Path:     path,
+			Size:     size,
+			Duration: time.Since(start).String(),
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(result)
+	})
+}
@@ -3,42 +3,429 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/XSAM/otelsql"
 	migrate "github.com/golang-migrate/migrate/v4"
+	sqlite3migrate "github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
 
 	// sqlite datbase driver
 	_ "github.com/mattn/go-sqlite3"
 
-	// migrate file source
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	// postgres database driver
+	_ "github.com/lib/pq"
 
 	// migrate database support
-	_ "github.com/golang-migrate/migrate/v4/database/sqlite3"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 )
 
+// Supported database drivers.
+const (
+	SQLite   = "sqlite3"
+	Postgres = "postgres"
+)
+
+// Memory may be used as the Filename to create a private in-memory sqlite
+// database. The database is lost when it is closed.
+const Memory = ":memory:"
+
 type Config struct {
-	Filename        string `kong:"required,default=./data/data.db"`
-	SchemaDirectory string `kong:",default=./schema"`
+	Driver string `kong:"default=sqlite3,enum='sqlite3,postgres'"`
+	// Filename is the sqlite database file.
+	Filename string `kong:"default=./data/data.db"`
+	// URL is the postgres connection string, in URL form.
+	URL string `kong:""`
+	// SchemaDirectory is a directory on disk containing migrations. It takes
+	// precedence over SchemaFS and is mostly useful during development.
+	SchemaDirectory string `kong:""`
+	// SchemaFS contains migrations, usually embedded in the binary.
+	SchemaFS fs.FS `kong:"-"`
+	// ReadOnly opens the database in read-only mode. Migrations are skipped as
+	// the database is expected to be managed by another process. For postgres,
+	// transactions default to read-only.
+	ReadOnly bool `kong:""`
+	// SlowQueryThreshold is how long a query may take before a warning is
+	// logged. Zero disables the warning.
+	SlowQueryThreshold time.Duration `kong:"default=100ms"`
+	// LoadShedThreshold is the average query duration at which requests are
+	// rejected and the server reports it is not ready. Zero disables load
+	// shedding.
+	LoadShedThreshold time.Duration `kong:"default=0"`
+	// Params are added to the sqlite DSN, such as _synchronous=NORMAL. They
+	// override the defaults of WAL journaling, a shared cache, and foreign key
+	// enforcement.
+	Params map[string]string `kong:""`
+	// BackupDirectory enables the sqlite backup endpoint, which writes
+	// backups to this directory.
+	BackupDirectory string `kong:""`
+	// TablePrefix is added to the name of each table and index, so several
+	// datasets may share a database. It must be an identifier.
+	TablePrefix string `kong:""`
+}
+
+// migrationsTable is the table used by migrate to track the applied version.
+func (c Config) migrationsTable() string {
+	return c.TablePrefix + "schema_migrations"
+}
+
+// dsn returns the sqlite DSN for name, with defaults overridden by Params.
+func (c Config) dsn(name string, defaults map[string]string) string {
+	values := url.Values{}
+
+	for k, v := range defaults {
+		values.Set(k, v)
+	}
+
+	for k, v := range c.Params {
+		values.Set(k, v)
+	}
+
+	return name + "?" + values.Encode()
 }
 
 func (c Config) Build(ctx context.Context) (*sql.DB, error) {
-	dsn := c.Filename + "?_journal_mode=WAL&cache=shared"
+	if err := ValidateTablePrefix(c.TablePrefix); err != nil {
+		return nil, err
+	}
+
+	switch c.Driver {
+	case "", SQLite:
+		return c.buildSQLite(ctx)
+	case Postgres:
+		return c.buildPostgres(ctx)
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", c.Driver)
+	}
+}
+
+func (c Config) buildSQLite(ctx context.Context) (*sql.DB, error) {
+	if c.Filename == Memory {
+		return c.buildMemory(ctx)
+	}
+
+	if c.ReadOnly {
+		return open(c.dsn(c.Filename, map[string]string{
+			"mode":          "ro",
+			"cache":         "shared",
+			"_foreign_keys": "on",
+		}))
+	}
+
+	dsn := c.dsn(c.Filename, map[string]string{
+		"_journal_mode": "WAL",
+		"cache":         "shared",
+		"_foreign_keys": "on",
+	})
+
+	if err := c.migrate("sqlite3://" + dsn); err != nil {
+		return nil, err
+	}
+
+	return open(dsn)
+}
+
+// each in-memory database gets a unique name so they are not shared between
+// calls to Build.
+var memoryCounter uint64
+
+func (c Config) buildMemory(ctx context.Context) (*sql.DB, error) {
+	name := fmt.Sprintf("memory%d", atomic.AddUint64(&memoryCounter, 1))
+
+	db, err := open(c.dsn(name, map[string]string{
+		"mode":          "memory",
+		"cache":         "shared",
+		"_foreign_keys": "on",
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	// An in-memory database is dropped when its last connection closes, so
+	// migrations must use this handle rather than opening their own.
+	src, err := c.source()
+	if err != nil || src == nil {
+		return db, err
+	}
 
-	if c.SchemaDirectory != "" {
-		m, err := migrate.New("file://"+c.SchemaDirectory, "sqlite3://"+dsn)
+	driver, err := sqlite3migrate.WithInstance(db, &sqlite3migrate.Config{
+		MigrationsTable: c.migrationsTable(),
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	m, err := migrate.NewWithInstance("schema", src, SQLite, driver)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func (c Config) buildPostgres(ctx context.Context) (*sql.DB, error) {
+	if c.URL == "" {
+		return nil, errors.New("postgres database URL must not be empty")
+	}
+
+	databaseURL := c.URL
+
+	if c.ReadOnly {
+		readOnly, err := readOnlyURL(databaseURL)
 		if err != nil {
 			return nil, err
 		}
 
-		if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-			return nil, err
+		databaseURL = readOnly
+	} else if err := c.migrate(databaseURL); err != nil {
+		return nil, err
+	}
+
+	return otelsql.Open("postgres", databaseURL, otelsql.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+	))
+}
+
+// readOnlyURL sets default_transaction_read_only for connections to the
+// postgres database at databaseURL, so writes are rejected by the server.
+// lib/pq sends unknown parameters to the server as settings.
+func readOnlyURL(databaseURL string) (string, error) {
+	u, err := url.Parse(databaseURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse postgres database URL %w", err)
+	}
+
+	values := u.Query()
+	values.Set("default_transaction_read_only", "on")
+	u.RawQuery = values.Encode()
+
+	return u.String(), nil
+}
+
+// migrate runs any pending migrations. It is a no-op if no schema is
+// configured.
+func (c Config) migrate(databaseURL string) error {
+	src, err := c.source()
+	if err != nil || src == nil {
+		return err
+	}
+
+	// both the sqlite and postgres drivers accept the table as a parameter
+	sep := "?"
+	if strings.Contains(databaseURL, "?") {
+		sep = "&"
+	}
+
+	databaseURL += sep + "x-migrations-table=" + url.QueryEscape(c.migrationsTable())
+
+	m, err := migrate.NewWithSourceInstance("schema", src, databaseURL)
+	if err != nil {
+		return err
+	}
+
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		return err
+	}
+
+	return nil
+}
+
+// source returns nil if no schema is configured. Migrations are read with
+// TablePrefix applied.
+func (c Config) source() (source.Driver, error) {
+	src, err := c.rawSource()
+	if err != nil || src == nil || c.TablePrefix == "" {
+		return src, err
+	}
+
+	return &prefixSource{Driver: src, prefix: c.TablePrefix}, nil
+}
+
+func (c Config) rawSource() (source.Driver, error) {
+	switch {
+	case c.SchemaDirectory != "":
+		// migrate's error for a missing directory does not name it
+		info, err := os.Stat(c.SchemaDirectory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema directory %q %w", c.SchemaDirectory, err)
+		}
+
+		if !info.IsDir() {
+			return nil, fmt.Errorf("schema directory %q is not a directory", c.SchemaDirectory)
 		}
 
+		return (&file.File{}).Open("file://" + c.SchemaDirectory)
+	case c.SchemaFS != nil:
+		return iofs.New(c.SchemaFS, ".")
+	default:
+		return nil, nil
 	}
+}
 
+func open(dsn string) (*sql.DB, error) {
 	return otelsql.Open("sqlite3", "file:"+dsn, otelsql.WithAttributes(
 		semconv.DBSystemSqlite,
 	))
 }
+
+// SchemaVersion returns the version of the latest migration in the configured
+// schema, or zero if there is no schema.
+func (c Config) SchemaVersion() (uint, error) {
+	src, err := c.source()
+	if err != nil || src == nil {
+		return 0, err
+	}
+
+	defer src.Close()
+
+	version, err := src.First()
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, nil
+		}
+
+		return 0, fmt.Errorf("failed to read first migration %w", err)
+	}
+
+	for {
+		next, err := src.Next(version)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return version, nil
+			}
+
+			return 0, fmt.Errorf("failed to read migration after %d %w", version, err)
+		}
+
+		version = next
+	}
+}
+
+// MigrationVersion returns the version of the last applied migration, and
+// whether it failed part way through, for a database without a TablePrefix.
+func MigrationVersion(ctx context.Context, db *sql.DB) (uint, bool, error) {
+	return Config{}.MigrationVersion(ctx, db)
+}
+
+// MigrationVersion returns the version of the last applied migration, and
+// whether it failed part way through.
+func (c Config) MigrationVersion(ctx context.Context, db *sql.DB) (uint, bool, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+
+	err := db.QueryRowContext(ctx, "select version, dirty from "+c.migrationsTable()+" limit 1").Scan(&version, &dirty)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read migration version %w", err)
+	}
+
+	return uint(version), dirty, nil
+}
+
+// CheckMigrations is Config.CheckMigrations for a database without a
+// TablePrefix.
+func CheckMigrations(ctx context.Context, db *sql.DB, version uint) error {
+	return Config{}.CheckMigrations(ctx, db, version)
+}
+
+// CheckMigrations returns an error if a migration failed part way through,
+// leaving the schema in an unknown state, or if migrations up to version have
+// not been applied.
+func (c Config) CheckMigrations(ctx context.Context, db *sql.DB, version uint) error {
+	applied, dirty, err := c.MigrationVersion(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		return fmt.Errorf("migration %d did not complete", applied)
+	}
+
+	if applied < version {
+		return fmt.Errorf("schema version %d is older than %d", applied, version)
+	}
+
+	return nil
+}
+
+// Rebind rewrites the "?" placeholders in query into the style used by
+// driver. Placeholders inside quoted strings are left untouched.
+func Rebind(driver string, query string) string {
+	if driver != Postgres {
+		return query
+	}
+
+	var (
+		b     strings.Builder
+		n     int
+		quote rune
+	)
+
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '?':
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// Ping is Config.Ping for a database without a TablePrefix.
+func Ping(ctx context.Context, db *sql.DB) error {
+	return Config{}.Ping(ctx, db)
+}
+
+// Ping verifies the database is reachable and that the tasks table can be
+// read. The context deadline is respected.
+func (c Config) Ping(ctx context.Context, db *sql.DB) error {
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database %w", err)
+	}
+
+	// reading a table catches unreadable files and a corrupt WAL, which a
+	// ping alone does not.
+	rows, err := db.QueryContext(ctx, ApplyTablePrefix(c.TablePrefix, "select 1 from {prefix}tasks limit 1"))
+	if err != nil {
+		return fmt.Errorf("failed to read database %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read database %w", err)
+	}
+
+	return nil
+}
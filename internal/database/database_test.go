@@ -0,0 +1,313 @@
+package database_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/twirp-todo-example/internal/database"
+	"github.com/bakins/twirp-todo-example/schema"
+)
+
+func TestReadOnly(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	dbfile := filepath.Join(t.TempDir(), "testing.db")
+
+	cfg := database.Config{
+		SchemaFS: schema.FS,
+		Filename: dbfile,
+	}
+
+	db, err := cfg.Build(ctx)
+	require.NoError(t, err)
+
+	_, err = db.ExecContext(ctx, "insert into tasks (title) values (?)", "testing")
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	cfg.ReadOnly = true
+
+	ro, err := cfg.Build(ctx)
+	require.NoError(t, err)
+
+	defer ro.Close()
+
+	var count int
+	err = ro.QueryRowContext(ctx, "select count(*) from tasks").Scan(&count)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+}
+
+func TestPing(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	cfg := database.Config{
+		SchemaFS: schema.FS,
+		Filename: filepath.Join(t.TempDir(), "testing.db"),
+	}
+
+	db, err := cfg.Build(ctx)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	require.NoError(t, database.Ping(ctx, db))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	require.Error(t, database.Ping(cancelled, db))
+}
+
+func TestRebind(t *testing.T) {
+	query := "insert into tasks (title, description) values (?, '?') where id = ?"
+
+	require.Equal(t, query, database.Rebind(database.SQLite, query))
+	require.Equal(t,
+		"insert into tasks (title, description) values ($1, '?') where id = $2",
+		database.Rebind(database.Postgres, query),
+	)
+}
+
+func TestMemory(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	cfg := database.Config{
+		SchemaFS: schema.FS,
+		Filename: database.Memory,
+	}
+
+	first, err := cfg.Build(ctx)
+	require.NoError(t, err)
+
+	defer first.Close()
+
+	second, err := cfg.Build(ctx)
+	require.NoError(t, err)
+
+	defer second.Close()
+
+	_, err = first.ExecContext(ctx, "insert into tasks (title) values (?)", "testing")
+	require.NoError(t, err)
+
+	// each in-memory database is private
+	var count int
+	err = second.QueryRowContext(ctx, "select count(*) from tasks").Scan(&count)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+}
+
+func TestCheckMigrations(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	cfg := database.Config{
+		SchemaFS: schema.FS,
+		Filename: filepath.Join(t.TempDir(), "testing.db"),
+	}
+
+	version, err := cfg.SchemaVersion()
+	require.NoError(t, err)
+	require.NotZero(t, version)
+
+	// the directory holds the same migrations as schema.FS
+	dirVersion, err := database.Config{SchemaDirectory: filepath.Join("..", "..", "schema")}.SchemaVersion()
+	require.NoError(t, err)
+	require.Equal(t, version, dirVersion)
+
+	db, err := cfg.Build(ctx)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	applied, dirty, err := database.MigrationVersion(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, version, applied)
+	require.False(t, dirty)
+
+	require.NoError(t, database.CheckMigrations(ctx, db, version))
+	require.Error(t, database.CheckMigrations(ctx, db, version+1))
+}
+
+func TestParams(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	cfg := database.Config{
+		SchemaFS: schema.FS,
+		Filename: filepath.Join(t.TempDir(), "testing.db"),
+		Params: map[string]string{
+			"_synchronous": "NORMAL",
+		},
+	}
+
+	db, err := cfg.Build(ctx)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	var foreignKeys int
+	require.NoError(t, db.QueryRowContext(ctx, "pragma foreign_keys").Scan(&foreignKeys))
+	require.Equal(t, 1, foreignKeys)
+
+	// NORMAL is 1
+	var synchronous int
+	require.NoError(t, db.QueryRowContext(ctx, "pragma synchronous").Scan(&synchronous))
+	require.Equal(t, 1, synchronous)
+
+	// foreign keys are enforced
+	_, err = db.ExecContext(ctx, "insert into task_tags (task_id, tag_id) values (?, ?)", 100, 100)
+	require.Error(t, err)
+}
+
+func TestBackup(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	cfg := database.Config{
+		SchemaFS: schema.FS,
+		Filename: filepath.Join(t.TempDir(), "testing.db"),
+	}
+
+	db, err := cfg.Build(ctx)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	_, err = db.ExecContext(ctx, "insert into tasks (title) values (?)", "testing")
+	require.NoError(t, err)
+
+	svr := httptest.NewServer(database.BackupHandler(db, t.TempDir()))
+	defer svr.Close()
+
+	resp, err := http.Get(svr.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	resp, err = http.Post(svr.URL, "", nil)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result database.BackupResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	require.NotZero(t, result.Size)
+
+	backup, err := database.Config{Filename: result.Path, ReadOnly: true}.Build(ctx)
+	require.NoError(t, err)
+
+	defer backup.Close()
+
+	var count int
+	require.NoError(t, backup.QueryRowContext(ctx, "select count(*) from tasks").Scan(&count))
+	require.Equal(t, 1, count)
+
+	// failures do not expose paths on the server
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	failing := httptest.NewServer(database.BackupHandler(db, missing))
+	defer failing.Close()
+
+	resp, err = http.Post(failing.URL, "", nil)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NotContains(t, string(body), missing)
+}
+
+func TestMissingSchemaDirectory(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	dir := filepath.Join(t.TempDir(), "missing")
+
+	cfg := database.Config{
+		SchemaDirectory: dir,
+		Filename:        filepath.Join(t.TempDir(), "testing.db"),
+	}
+
+	_, err := cfg.Build(ctx)
+	require.ErrorIs(t, err, os.ErrNotExist)
+	require.Contains(t, err.Error(), dir)
+}
+
+func TestTablePrefix(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	cfg := database.Config{
+		SchemaFS:    schema.FS,
+		Filename:    filepath.Join(t.TempDir(), "testing.db"),
+		TablePrefix: "tenant_",
+	}
+
+	db, err := cfg.Build(ctx)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	_, err = db.ExecContext(ctx, "insert into tenant_tasks (title) values (?)", "testing")
+	require.NoError(t, err)
+
+	// indexes are prefixed too, and no unprefixed tables are created.
+	// version_unique is created by migrate on its migrations table.
+	var names []string
+
+	rows, err := db.QueryContext(ctx, "select name from sqlite_master where name not like 'tenant\\_%' escape '\\' and name not like 'sqlite\\_%' escape '\\' and name != 'version_unique'")
+	require.NoError(t, err)
+
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+
+	require.NoError(t, rows.Err())
+	require.NoError(t, rows.Close())
+	require.Empty(t, names)
+
+	var index int
+	require.NoError(t, db.QueryRowContext(ctx, "select count(*) from sqlite_master where type = 'index' and name = 'tenant_tasks_owner'").Scan(&index))
+	require.Equal(t, 1, index)
+
+	version, err := cfg.SchemaVersion()
+	require.NoError(t, err)
+	require.NoError(t, cfg.CheckMigrations(ctx, db, version))
+	require.NoError(t, cfg.Ping(ctx, db))
+
+	// a second prefix is migrated separately in the same database
+	other := cfg
+	other.TablePrefix = "other_"
+
+	otherDB, err := other.Build(ctx)
+	require.NoError(t, err)
+
+	defer otherDB.Close()
+
+	require.NoError(t, other.CheckMigrations(ctx, otherDB, version))
+
+	cfg.TablePrefix = "bad; drop table tasks"
+
+	_, err = cfg.Build(ctx)
+	require.Error(t, err)
+}
@@ -0,0 +1,156 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// tablePrefixPattern limits prefixes to identifiers, as they are added to
+// queries directly. Postgres truncates identifiers longer than 63 bytes, so
+// the prefix is kept short enough for the longest index name.
+var tablePrefixPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,31}$`)
+
+// ValidateTablePrefix returns an error if prefix is not a valid TablePrefix.
+// An empty prefix is valid.
+func ValidateTablePrefix(prefix string) error {
+	if prefix != "" && !tablePrefixPattern.MatchString(prefix) {
+		return fmt.Errorf("table prefix %q must be a letter followed by at most 31 letters, digits, or underscores", prefix)
+	}
+
+	return nil
+}
+
+// ApplyTablePrefix replaces each {prefix} in query, which precedes table
+// names in queries. prefix must be valid.
+func ApplyTablePrefix(prefix string, query string) string {
+	return strings.ReplaceAll(query, "{prefix}", prefix)
+}
+
+// createdPattern finds the names of tables and indexes created, or renamed,
+// by a migration.
+var createdPattern = regexp.MustCompile(`(?i)\b(?:create\s+(?:temp\s+|temporary\s+)?(?:unique\s+)?(?:table|index)\s+(?:if\s+not\s+exists\s+)?|rename\s+to\s+)(\w+)`)
+
+// prefixSource applies a table prefix to migrations as they are read.
+// Migrations do not mention the prefix, so every table and index they create
+// is found first, and each use of those names is prefixed. This leaves
+// migrations that have already been applied without a prefix unchanged.
+type prefixSource struct {
+	source.Driver
+	prefix string
+
+	once  sync.Once
+	names *regexp.Regexp
+	err   error
+}
+
+func (s *prefixSource) ReadUp(version uint) (io.ReadCloser, string, error) {
+	r, identifier, err := s.Driver.ReadUp(version)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return s.apply(r, identifier)
+}
+
+func (s *prefixSource) ReadDown(version uint) (io.ReadCloser, string, error) {
+	r, identifier, err := s.Driver.ReadDown(version)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return s.apply(r, identifier)
+}
+
+func (s *prefixSource) apply(r io.ReadCloser, identifier string) (io.ReadCloser, string, error) {
+	defer r.Close()
+
+	s.once.Do(func() {
+		s.names, s.err = s.createdNames()
+	})
+
+	if s.err != nil {
+		return nil, "", s.err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read migration %s %w", identifier, err)
+	}
+
+	query := string(data)
+	if s.names != nil {
+		query = s.names.ReplaceAllString(query, s.prefix+"$1")
+	}
+
+	return io.NopCloser(strings.NewReader(query)), identifier, nil
+}
+
+// createdNames returns a pattern matching the name of each table and index
+// created by any migration, or nil if there are none.
+func (s *prefixSource) createdNames() (*regexp.Regexp, error) {
+	names := map[string]bool{}
+
+	read := func(r io.ReadCloser, identifier string, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		defer r.Close()
+
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("failed to read migration %s %w", identifier, err)
+		}
+
+		for _, match := range createdPattern.FindAllStringSubmatch(string(data), -1) {
+			names[strings.ToLower(match[1])] = true
+		}
+
+		return nil
+	}
+
+	version, err := s.Driver.First()
+
+	for err == nil {
+		up, identifier, readErr := s.Driver.ReadUp(version)
+		if err := read(up, identifier, readErr); err != nil {
+			return nil, err
+		}
+
+		down, identifier, readErr := s.Driver.ReadDown(version)
+		if err := read(down, identifier, readErr); err != nil {
+			return nil, err
+		}
+
+		version, err = s.Driver.Next(version)
+	}
+
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read migrations %w", err)
+	}
+
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	quoted := make([]string, 0, len(names))
+	for name := range names {
+		quoted = append(quoted, regexp.QuoteMeta(name))
+	}
+
+	sort.Strings(quoted)
+
+	return regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`), nil
+}
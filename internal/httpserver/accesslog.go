@@ -0,0 +1,54 @@
+package httpserver
+
+import (
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bakins/twirp-todo-example/internal/logging"
+	"github.com/bakins/twirp-todo-example/internal/stackdriver"
+)
+
+// AccessLog is middleware that logs a line for every request, including the
+// Stackdriver httpRequest field. The logger in the request context is used, so
+// it should be added after logging.Middleware. When the request has an id, the
+// request's log lines are grouped as an operation, ending with the access log
+// line. The line is written even if a later handler panics.
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		fields := make([]zap.Field, 0, 2)
+
+		if id := logging.RequestIDFromContext(r.Context()); id != "" {
+			r = r.WithContext(logging.WithOperation(r.Context(), id))
+			fields = append(fields, stackdriver.OperationLast())
+		}
+
+		rw := NewResponseRecorder(w)
+
+		defer func() {
+			status := rw.StatusCode
+
+			// a panic that was not recovered aborts the response
+			if p := recover(); p != nil {
+				status = http.StatusInternalServerError
+
+				defer panic(p)
+			}
+
+			req := stackdriver.NewHTTPRequest(r, status, rw.BytesWritten, time.Since(start))
+			fields = append(fields, stackdriver.HTTP(req))
+
+			if status >= http.StatusInternalServerError {
+				logging.Warn(r.Context(), "request", fields...)
+				return
+			}
+
+			logging.Info(r.Context(), "request", fields...)
+		}()
+
+		next.ServeHTTP(rw, r)
+	})
+}
@@ -0,0 +1,41 @@
+package httpserver
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+
+	"github.com/twitchtv/twirp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BasicAuth returns middleware that requires HTTP basic auth with username and
+// a password matching the bcrypt passwordHash. Other requests receive an
+// unauthenticated Twirp error, which is a 401, with a WWW-Authenticate header.
+// Use it with AddMiddlewareFor to only protect some paths.
+func BasicAuth(username string, passwordHash string) (func(http.Handler) http.Handler, error) {
+	if _, err := bcrypt.Cost([]byte(passwordHash)); err != nil {
+		return nil, fmt.Errorf("failed to parse password hash %w", err)
+	}
+
+	middleware := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, password, ok := r.BasicAuth()
+
+			// the password is always compared, so the time taken does not
+			// reveal whether the username was correct.
+			userOK := subtle.ConstantTimeCompare([]byte(user), []byte(username)) == 1
+			passwordOK := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password)) == nil
+
+			if !ok || !userOK || !passwordOK {
+				w.Header().Set("WWW-Authenticate", `Basic realm="todo", charset="UTF-8"`)
+				_ = twirp.WriteError(w, twirp.NewError(twirp.Unauthenticated, "invalid credentials"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	return middleware, nil
+}
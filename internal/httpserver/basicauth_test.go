@@ -0,0 +1,110 @@
+package httpserver_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/bakins/twirp-todo-example/internal/httpserver"
+)
+
+func TestBasicAuth(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	svr, err := httpserver.New(httpserver.WithBasicAuth("admin", string(hash), "/private/"))
+	require.NoError(t, err)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	})
+
+	svr.Handle("/private/", handler)
+	svr.Handle("/public", handler)
+
+	addr := startServer(t, svr)
+	base := "http://" + addr.String()
+
+	tests := []struct {
+		path     string
+		user     string
+		password string
+		expected int
+	}{
+		{path: "/public", expected: http.StatusOK},
+		{path: "/private/data", expected: http.StatusUnauthorized},
+		{path: "/private/data", user: "admin", password: "wrong", expected: http.StatusUnauthorized},
+		{path: "/private/data", user: "other", password: "secret", expected: http.StatusUnauthorized},
+		{path: "/private/data", user: "admin", password: "secret", expected: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req, err := http.NewRequest(http.MethodGet, base+tt.path, nil)
+		require.NoError(t, err)
+
+		if tt.user != "" {
+			req.SetBasicAuth(tt.user, tt.password)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		_ = resp.Body.Close()
+
+		require.Equal(t, tt.expected, resp.StatusCode)
+
+		if tt.expected == http.StatusUnauthorized {
+			require.Contains(t, resp.Header.Get("WWW-Authenticate"), "Basic")
+		}
+	}
+
+	_, err = httpserver.New(httpserver.WithBasicAuth("admin", "not a hash", "/"))
+	require.Error(t, err)
+}
+
+func TestBasicAuthAdmin(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	admin, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	svr, err := httpserver.New(
+		httpserver.WithAdminListener(admin),
+		httpserver.WithBasicAuth("admin", string(hash), "/metrics"),
+	)
+	require.NoError(t, err)
+
+	svr.HandleAdmin("/metrics", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+
+	_ = startServer(t, svr)
+
+	adminAddr, err := svr.WaitForAdminAddress(context.Background())
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+adminAddr.String()+"/metrics", nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	_ = resp.Body.Close()
+
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req.SetBasicAuth("admin", "secret")
+
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	_ = resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
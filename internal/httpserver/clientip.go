@@ -0,0 +1,90 @@
+package httpserver
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/twitchtv/twirp"
+)
+
+// ParseCIDRs parses IPv4 and IPv6 CIDRs, such as 10.0.0.0/8 or fd00::/8.
+func ParseCIDRs(cidrs ...string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CIDR %q %w", c, err)
+		}
+
+		nets = append(nets, n)
+	}
+
+	return nets, nil
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ClientIP returns the address of the client that made r, or nil if it cannot
+// be parsed. When the peer is a trusted proxy, X-Forwarded-For is read from
+// right to left and the first address that is not a trusted proxy is used, so
+// clients cannot spoof their address by sending the header themselves.
+func ClientIP(r *http.Request, trustedProxies []*net.IPNet) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !containsIP(trustedProxies, ip) {
+		return ip
+	}
+
+	// proxies may each add a header or append to an existing one
+	var forwarded []string
+	for _, h := range r.Header.Values("X-Forwarded-For") {
+		forwarded = append(forwarded, strings.Split(h, ",")...)
+	}
+
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		next := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if next == nil {
+			return ip
+		}
+
+		ip = next
+
+		if !containsIP(trustedProxies, ip) {
+			return ip
+		}
+	}
+
+	return ip
+}
+
+// Allowlist returns middleware that rejects requests from clients outside of
+// allowed with a permission_denied Twirp error, which is a 403. The client
+// address is found using ClientIP.
+func Allowlist(allowed []*net.IPNet, trustedProxies []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := ClientIP(r, trustedProxies)
+			if ip == nil || !containsIP(allowed, ip) {
+				_ = twirp.WriteError(w, twirp.NewError(twirp.PermissionDenied, "client address is not allowed"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
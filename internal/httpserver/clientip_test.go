@@ -0,0 +1,82 @@
+package httpserver_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/twirp-todo-example/internal/httpserver"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted, err := httpserver.ParseCIDRs("10.0.0.0/8", "fd00::/8")
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		remote    string
+		forwarded []string
+		expected  string
+	}{
+		"direct": {
+			remote:   "192.0.2.1:1234",
+			expected: "192.0.2.1",
+		},
+		"untrusted peer is not believed": {
+			remote:    "192.0.2.1:1234",
+			forwarded: []string{"198.51.100.1"},
+			expected:  "192.0.2.1",
+		},
+		"trusted proxy": {
+			remote:    "10.0.0.1:1234",
+			forwarded: []string{"198.51.100.1"},
+			expected:  "198.51.100.1",
+		},
+		"spoofed header": {
+			remote:    "10.0.0.1:1234",
+			forwarded: []string{"203.0.113.1, 198.51.100.1, 10.0.0.2"},
+			expected:  "198.51.100.1",
+		},
+		"multiple headers": {
+			remote:    "[fd00::1]:1234",
+			forwarded: []string{"2001:db8::1", "fd00::2"},
+			expected:  "2001:db8::1",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remote
+
+			for _, f := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", f)
+			}
+
+			require.Equal(t, tt.expected, httpserver.ClientIP(r, trusted).String())
+		})
+	}
+}
+
+func TestAdminAllowlist(t *testing.T) {
+	for cidr, expected := range map[string]int{
+		"127.0.0.0/8":  http.StatusOK,
+		"192.0.2.0/24": http.StatusForbidden,
+	} {
+		svr, err := httpserver.New(httpserver.WithAdminAllowlist(cidr))
+		require.NoError(t, err)
+
+		addr := startServer(t, svr)
+
+		resp, err := http.Get("http://" + addr.String() + "/healthz")
+		require.NoError(t, err)
+
+		_ = resp.Body.Close()
+
+		require.Equal(t, expected, resp.StatusCode, cidr)
+	}
+
+	_, err := httpserver.New(httpserver.WithAdminAllowlist("not a cidr"))
+	require.Error(t, err)
+}
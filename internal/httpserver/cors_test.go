@@ -0,0 +1,66 @@
+package httpserver_test
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/twirp-todo-example/internal/httpserver"
+)
+
+func TestCORS(t *testing.T) {
+	svr, err := httpserver.New(httpserver.WithAllowedOrigins("https://example.com"))
+	require.NoError(t, err)
+
+	svr.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+
+	addr := startServer(t, svr)
+	url := "http://" + addr.String() + "/twirp/bakins.todo.v1.TodoService/ListTasks"
+
+	tests := map[string]struct {
+		method   string
+		origin   string
+		status   int
+		expected string
+	}{
+		"allowed": {
+			method:   http.MethodPost,
+			origin:   "https://example.com",
+			status:   http.StatusOK,
+			expected: "https://example.com",
+		},
+		"not allowed": {
+			method: http.MethodPost,
+			origin: "https://evil.example",
+			status: http.StatusOK,
+		},
+		"preflight": {
+			method:   http.MethodOptions,
+			origin:   "https://example.com",
+			status:   http.StatusNoContent,
+			expected: "https://example.com",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, url, nil)
+			require.NoError(t, err)
+
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+
+			_ = resp.Body.Close()
+
+			require.Equal(t, tt.status, resp.StatusCode)
+			require.Equal(t, tt.expected, resp.Header.Get("Access-Control-Allow-Origin"))
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package httpserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/twitchtv/twirp"
+	"go.opentelemetry.io/otel/metric/global"
+	"go.opentelemetry.io/otel/metric/instrument"
+)
+
+// tracker counts open connections and in-flight requests so that shutdown
+// can wait for requests to finish. Requests on h2c connections are not seen
+// by http.Server once the connection is upgraded, so they are counted here.
+type tracker struct {
+	draining int32
+	inFlight int64
+
+	connLock sync.Mutex
+	conns    map[net.Conn]http.ConnState
+}
+
+func newTracker() *tracker {
+	return &tracker{
+		conns: map[net.Conn]http.ConnState{},
+	}
+}
+
+// register adds gauges for open connections and in-flight requests.
+func (t *tracker) register() error {
+	meter := global.Meter("github.com/bakins/twirp-todo-example/internal/httpserver")
+
+	requests, err := meter.AsyncInt64().Gauge(
+		"http.server.active_requests",
+		instrument.WithDescription("number of in-flight HTTP requests"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create active requests gauge %w", err)
+	}
+
+	conns, err := meter.AsyncInt64().Gauge(
+		"http.server.open_connections",
+		instrument.WithDescription("number of open HTTP connections"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create open connections gauge %w", err)
+	}
+
+	err = meter.RegisterCallback(
+		[]instrument.Asynchronous{requests, conns},
+		func(ctx context.Context) {
+			requests.Observe(ctx, t.requests())
+			conns.Observe(ctx, t.openConnections())
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register gauge callback %w", err)
+	}
+
+	return nil
+}
+
+// connState is used as http.Server.ConnState.
+func (t *tracker) connState(c net.Conn, state http.ConnState) {
+	t.connLock.Lock()
+	defer t.connLock.Unlock()
+
+	switch state {
+	case http.StateHijacked, http.StateClosed:
+		delete(t.conns, c)
+	default:
+		t.conns[c] = state
+	}
+}
+
+func (t *tracker) openConnections() int64 {
+	t.connLock.Lock()
+	defer t.connLock.Unlock()
+
+	return int64(len(t.conns))
+}
+
+func (t *tracker) requests() int64 {
+	return atomic.LoadInt64(&t.inFlight)
+}
+
+func (t *tracker) isDraining() bool {
+	return atomic.LoadInt32(&t.draining) == 1
+}
+
+// drain stops new requests from being accepted and waits for in-flight
+// requests to finish or for ctx to be done.
+func (t *tracker) drain(ctx context.Context) error {
+	atomic.StoreInt32(&t.draining, 1)
+
+	// requests finish quickly in the common case, so polling is fine here.
+	ticker := time.NewTicker(time.Millisecond * 50)
+	defer ticker.Stop()
+
+	for t.requests() > 0 {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d requests still in flight %w", t.requests(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+
+	return nil
+}
+
+// middleware counts in-flight requests and refuses new ones with 503 Service
+// Unavailable while draining. Health checks are always served so that load
+// balancers can see the server is going away.
+func (t *tracker) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if t.isDraining() && r.URL.Path != "/healthz" && r.URL.Path != "/readyz" {
+			w.Header().Set("Connection", "close")
+			_ = twirp.WriteError(w, twirp.NewError(twirp.Unavailable, "server is shutting down"))
+			return
+		}
+
+		atomic.AddInt64(&t.inFlight, 1)
+		defer atomic.AddInt64(&t.inFlight, -1)
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,80 @@
+package httpserver_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/twirp-todo-example/internal/httpserver"
+)
+
+func TestDrain(t *testing.T) {
+	svr, err := httpserver.New()
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	svr.Handle("/slow", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		_, _ = io.WriteString(w, "ok")
+	}))
+
+	svr.Handle("/fast", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- svr.Run(ctx)
+	}()
+
+	addr, err := svr.WaitForAddress(ctx)
+	require.NoError(t, err)
+
+	base := "http://" + addr.String()
+
+	slow := make(chan int, 1)
+	go func() {
+		resp, err := http.Get(base + "/slow")
+		if err != nil {
+			slow <- 0
+			return
+		}
+		_ = resp.Body.Close()
+		slow <- resp.StatusCode
+	}()
+
+	<-started
+	require.Equal(t, int64(1), svr.InFlight())
+
+	cancel()
+
+	// new requests are refused and readiness fails while draining
+	require.Eventually(t, func() bool {
+		resp, err := http.Get(base + "/readyz")
+		if err != nil {
+			return false
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode == http.StatusServiceUnavailable
+	}, time.Second*5, time.Millisecond*10)
+
+	resp, err := http.Get(base + "/fast")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	close(release)
+
+	require.Equal(t, http.StatusOK, <-slow)
+	require.NoError(t, <-errCh)
+}
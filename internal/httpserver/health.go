@@ -0,0 +1,107 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bakins/twirp-todo-example/internal/logging"
+	"github.com/bakins/twirp-todo-example/internal/metadata"
+)
+
+type healthCheck struct {
+	name  string
+	check func(context.Context) error
+}
+
+// AddHealthCheck registers a readiness check. Every check is run on each
+// request to /readyz, which responds with 503 Service Unavailable if any of
+// them fail. The response only names the failing checks, as /readyz is not
+// authenticated. Errors are logged.
+func (s *Server) AddHealthCheck(name string, check func(context.Context) error) {
+	s.healthLock.Lock()
+	defer s.healthLock.Unlock()
+
+	s.healthChecks = append(s.healthChecks, healthCheck{name: name, check: check})
+}
+
+type checkResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+type healthResponse struct {
+	Status string        `json:"status"`
+	Checks []checkResult `json:"checks,omitempty"`
+}
+
+const (
+	statusOK       = "ok"
+	statusError    = "error"
+	statusDraining = "draining"
+)
+
+// serveHealthz is a liveness check. It only verifies the server can respond.
+func serveHealthz(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, http.StatusOK, healthResponse{Status: statusOK})
+}
+
+// serveVersion describes the running binary. It does not depend on any
+// other services so it can be used while the server is starting.
+func serveVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	_ = json.NewEncoder(w).Encode(metadata.BuildInfo())
+}
+
+func (s *Server) serveReadyz(w http.ResponseWriter, r *http.Request) {
+	if s.tracker.isDraining() {
+		writeHealth(w, http.StatusServiceUnavailable, healthResponse{Status: statusDraining})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+	defer cancel()
+
+	s.healthLock.RLock()
+	checks := s.healthChecks
+	s.healthLock.RUnlock()
+
+	resp := healthResponse{
+		Status: statusOK,
+		Checks: make([]checkResult, 0, len(checks)),
+	}
+
+	code := http.StatusOK
+
+	for _, c := range checks {
+		result := checkResult{
+			Name:   c.name,
+			Status: statusOK,
+		}
+
+		if err := c.check(ctx); err != nil {
+			logging.Error(ctx, "health check failed", zap.String("check", c.name), zap.Error(err))
+
+			result.Status = statusError
+			resp.Status = statusError
+			code = http.StatusServiceUnavailable
+		}
+
+		resp.Checks = append(resp.Checks, result)
+	}
+
+	writeHealth(w, code, resp)
+}
+
+func writeHealth(w http.ResponseWriter, code int, resp healthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+
+	_ = json.NewEncoder(w).Encode(resp)
+}
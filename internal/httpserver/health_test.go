@@ -0,0 +1,106 @@
+package httpserver_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/bakins/twirp-todo-example/internal/httpserver"
+	"github.com/bakins/twirp-todo-example/internal/metadata"
+)
+
+func TestHealthChecks(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	core, logs := observer.New(zapcore.InfoLevel)
+
+	svr, err := httpserver.New(httpserver.WithLogger(zap.New(core)))
+	require.NoError(t, err)
+
+	var (
+		lock    sync.Mutex
+		failing error
+	)
+
+	svr.AddHealthCheck("database", func(ctx context.Context) error {
+		lock.Lock()
+		defer lock.Unlock()
+
+		return failing
+	})
+
+	addr := startServer(t, svr)
+
+	get := func(path string) (int, map[string]interface{}) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+addr.String()+path, nil)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		defer resp.Body.Close()
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+		return resp.StatusCode, body
+	}
+
+	code, body := get("/healthz")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "ok", body["status"])
+
+	code, body = get("/readyz")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "ok", body["status"])
+
+	lock.Lock()
+	failing = errors.New("database is down")
+	lock.Unlock()
+
+	code, body = get("/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "error", body["status"])
+
+	checks, ok := body["checks"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, checks, 1)
+
+	check := checks[0].(map[string]interface{})
+	require.Equal(t, map[string]interface{}{"name": "database", "status": "error"}, check)
+
+	// the error is only logged
+	entries := logs.FilterMessage("health check failed").All()
+	require.Len(t, entries, 1)
+	require.Equal(t, "database", entries[0].ContextMap()["check"])
+	require.Equal(t, "database is down", entries[0].ContextMap()["error"])
+}
+
+func TestVersion(t *testing.T) {
+	svr, err := httpserver.New()
+	require.NoError(t, err)
+
+	addr := startServer(t, svr)
+
+	resp, err := http.Get("http://" + addr.String() + "/version")
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body metadata.Build
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Equal(t, runtime.Version(), body.GoVersion)
+}
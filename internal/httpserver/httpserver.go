@@ -1,34 +1,89 @@
 package httpserver
 
 import (
+	"compress/gzip"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
-	"sync/atomic"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/NYTimes/gziphandler"
 	"github.com/justinas/alice"
+	"go.uber.org/zap"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/bakins/twirp-reflection/reflection"
+
+	"github.com/bakins/twirp-todo-example/internal/logging"
 )
 
 type Config struct {
-	Address string `kong:"default=127.0.0.1:8080"`
+	Address           string        `kong:"default=127.0.0.1:8080"`
+	TLSCertFile       string        `kong:""`
+	TLSKeyFile        string        `kong:""`
+	ReadHeaderTimeout time.Duration `kong:"default=10s"`
+	ReadTimeout       time.Duration `kong:"default=30s"`
+	// WriteTimeout is zero by default, as it would cut off streaming
+	// responses. RPCs are bounded by the timeout interceptor instead.
+	WriteTimeout          time.Duration `kong:"default=0"`
+	IdleTimeout           time.Duration `kong:"default=120s"`
+	ShutdownTimeout       time.Duration `kong:"default=10s"`
+	AllowedOrigins        []string      `kong:""`
+	Gzip                  bool          `kong:"default=true,negatable"`
+	GzipLevel             int           `kong:"default=-1"`
+	H2C                   bool          `kong:"default=true,negatable"`
+	MaxConcurrentRequests int           `kong:"default=0"`
+	Telemetry             bool          `kong:""`
+	AdminAddress          string        `kong:""`
+	PublicAdmin           bool          `kong:""`
+	Pprof                 bool          `kong:""`
+	MaxRequestBytes       int64         `kong:"default=1048576"`
+	AdminAllowedCIDRs     []string      `kong:""`
+	TrustedProxies        []string      `kong:""`
+	BasicAuthUsername     string        `kong:""`
+	BasicAuthPasswordHash string        `kong:"env=BASIC_AUTH_PASSWORD_HASH"`
+	BasicAuthPaths        []string      `kong:""`
+	RateLimit             float64       `kong:"default=0"`
+	RateLimitBurst        int           `kong:"default=10"`
 }
 
+// Build creates a server that logs with the logger in ctx.
 func (c Config) Build(ctx context.Context) (*Server, error) {
-	return New(WithConfig(c))
+	return New(WithConfig(c), WithLogger(logging.FromContext(ctx)))
 }
 
 type serverConfig struct {
-	network string
-	address string
+	network         string
+	address         string
+	tls             *tls.Config
+	timeouts        Timeouts
+	shutdownTimeout time.Duration
+	allowedOrigins  []string
+	gzip            bool
+	gzipLevel       int
+	h2c             bool
+	listener        net.Listener
+	maxConcurrent   int
+	telemetry       bool
+	adminAddress    string
+	adminListener   net.Listener
+	publicAdmin     bool
+	pprof           bool
+	maxRequestBytes int64
+	adminAllowed    []*net.IPNet
+	trustedProxies  []*net.IPNet
+	basicAuth       func(http.Handler) http.Handler
+	basicAuthPaths  []string
+	rateLimit       float64
+	rateLimitBurst  int
+	logger          *zap.Logger
 }
 
 type Option interface {
@@ -54,11 +109,33 @@ func (s serverOptions) apply(c *serverConfig) error {
 }
 
 type Server struct {
-	chain      alice.Chain
-	listener   atomic.Value
+	chain alice.Chain
+	// adminChain holds the middleware added with AddMiddleware, for the
+	// admin listener.
+	adminChain alice.Chain
+	addr       *boundAddress
 	mux        *http.ServeMux
-	reflection *reflection.Server
-	config     *serverConfig
+	adminAddr  *boundAddress
+	// admin is nil when operational endpoints are served by mux.
+	admin        *http.ServeMux
+	reflection   *reflection.Server
+	config       *serverConfig
+	tracker      *tracker
+	healthLock   sync.RWMutex
+	healthChecks []healthCheck
+	// streams are the patterns added with HandleStream, which are not
+	// compressed.
+	streams []string
+	// onShutdown is run when a graceful shutdown begins.
+	onShutdown []func()
+	// stop is closed by Shutdown.
+	stop     chan struct{}
+	stopOnce sync.Once
+	// running is closed when Run is called and done when it returns.
+	running chan struct{}
+	runOnce sync.Once
+	done    chan struct{}
+	runErr  error
 }
 
 func WithServerAddress(network string, address string) Option {
@@ -81,9 +158,361 @@ func WithServerAddress(network string, address string) Option {
 	})
 }
 
+// WithTLS configures the server to serve HTTPS using the given certificate and
+// key files. HTTP/2 is negotiated using ALPN.
+func WithTLS(certFile string, keyFile string) Option {
+	return serverOptionFunc(func(c *serverConfig) error {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load TLS certificate %q and key %q %w", certFile, keyFile, err)
+		}
+
+		c.tls = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+
+		return nil
+	})
+}
+
+// Timeouts for the underlying http.Server. A zero value means no timeout.
+type Timeouts struct {
+	ReadHeader time.Duration
+	Read       time.Duration
+	Write      time.Duration
+	Idle       time.Duration
+}
+
+// WithLogger adds logger to the context of each request, so panics and
+// requests are logged. Nothing is logged by default.
+func WithLogger(logger *zap.Logger) Option {
+	return serverOptionFunc(func(c *serverConfig) error {
+		if logger == nil {
+			return errors.New("logger must not be nil")
+		}
+
+		c.logger = logger
+
+		return nil
+	})
+}
+
+// WithTimeouts sets the timeouts for the underlying http.Server.
+// The default is no timeouts.
+func WithTimeouts(t Timeouts) Option {
+	return serverOptionFunc(func(c *serverConfig) error {
+		if t.ReadHeader < 0 || t.Read < 0 || t.Write < 0 || t.Idle < 0 {
+			return errors.New("timeouts must not be negative")
+		}
+
+		c.timeouts = t
+
+		return nil
+	})
+}
+
+// WithShutdownTimeout sets how long Run waits for in-flight requests to
+// finish once its context is cancelled. The default is 10 seconds.
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return serverOptionFunc(func(c *serverConfig) error {
+		if timeout <= 0 {
+			return errors.New("shutdown timeout must be positive")
+		}
+
+		c.shutdownTimeout = timeout
+
+		return nil
+	})
+}
+
+// WithAllowedOrigins enables CORS for requests from the given origins.
+// An origin of "*" allows any origin. The default is to not add CORS headers.
+func WithAllowedOrigins(origins ...string) Option {
+	return serverOptionFunc(func(c *serverConfig) error {
+		for _, o := range origins {
+			if o == "" {
+				return errors.New("allowed origin must not be empty")
+			}
+		}
+
+		c.allowedOrigins = append(c.allowedOrigins, origins...)
+
+		return nil
+	})
+}
+
+// WithGzip enables or disables gzip compression of responses.
+// The default is enabled.
+func WithGzip(enabled bool) Option {
+	return serverOptionFunc(func(c *serverConfig) error {
+		c.gzip = enabled
+
+		return nil
+	})
+}
+
+// WithGzipLevel sets the gzip compression level. The default is
+// gzip.DefaultCompression.
+func WithGzipLevel(level int) Option {
+	return serverOptionFunc(func(c *serverConfig) error {
+		if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+			return fmt.Errorf("invalid gzip level %d", level)
+		}
+
+		c.gzipLevel = level
+
+		return nil
+	})
+}
+
+// WithHTTP2 enables or disables plaintext HTTP/2 (h2c). When disabled, plaintext
+// requests are served using HTTP/1.1 only, which is useful behind a proxy that
+// speaks HTTP/2 to clients itself. HTTP/2 over TLS is not affected.
+// The default is enabled.
+func WithHTTP2(enabled bool) Option {
+	return serverOptionFunc(func(c *serverConfig) error {
+		c.h2c = enabled
+
+		return nil
+	})
+}
+
+// WithListener serves on an existing listener rather than creating one in Run.
+// The server address options are ignored. This is useful for tests and socket
+// activation. The listener is closed when Run returns.
+func WithListener(l net.Listener) Option {
+	return serverOptionFunc(func(c *serverConfig) error {
+		if l == nil {
+			return errors.New("listener must not be nil")
+		}
+
+		c.listener = l
+
+		return nil
+	})
+}
+
+// WithMaxConcurrentRequests limits the number of requests handled at once.
+// The default is no limit.
+func WithMaxConcurrentRequests(limit int) Option {
+	return serverOptionFunc(func(c *serverConfig) error {
+		if limit < 0 {
+			return errors.New("max concurrent requests must not be negative")
+		}
+
+		c.maxConcurrent = limit
+
+		return nil
+	})
+}
+
+// WithMaxRequestBytes limits the size of request bodies. Zero means no limit.
+// The default is 1MiB.
+func WithMaxRequestBytes(limit int64) Option {
+	return serverOptionFunc(func(c *serverConfig) error {
+		if limit < 0 {
+			return errors.New("max request bytes must not be negative")
+		}
+
+		c.maxRequestBytes = limit
+
+		return nil
+	})
+}
+
+// WithTelemetry enables or disables OpenTelemetry spans and metrics for every
+// request. Twirp services are usually instrumented by an interceptor, so this is
+// mostly useful for other handlers. The default is disabled.
+func WithTelemetry(enabled bool) Option {
+	return serverOptionFunc(func(c *serverConfig) error {
+		c.telemetry = enabled
+
+		return nil
+	})
+}
+
+// WithAdminAddress serves the operational endpoints added with HandleAdmin on a
+// separate plaintext listener, so they are not exposed with the API. The
+// network is the same as the main listener. The default is to only serve the
+// health endpoints, on the main listener. See WithPublicAdmin.
+func WithAdminAddress(address string) Option {
+	return serverOptionFunc(func(c *serverConfig) error {
+		if address == "" {
+			return errors.New("admin address must not be empty")
+		}
+
+		c.adminAddress = address
+
+		return nil
+	})
+}
+
+// WithAdminListener is like WithAdminAddress, but serves on an existing
+// listener. The listener is closed when Run returns.
+func WithAdminListener(l net.Listener) Option {
+	return serverOptionFunc(func(c *serverConfig) error {
+		if l == nil {
+			return errors.New("admin listener must not be nil")
+		}
+
+		c.adminListener = l
+
+		return nil
+	})
+}
+
+// WithPublicAdmin serves the endpoints added with HandleAdmin on the main
+// listener when there is no admin listener. They may change the server or
+// expose its data, so they should be protected with an allowlist or basic
+// auth. The default is disabled.
+func WithPublicAdmin(enabled bool) Option {
+	return serverOptionFunc(func(c *serverConfig) error {
+		c.publicAdmin = enabled
+
+		return nil
+	})
+}
+
+// WithAdminAllowlist only allows clients within the CIDRs to reach the
+// endpoints added with HandleAdmin. Other clients receive a 403. Note this
+// includes health checks. The default is to allow every client.
+func WithAdminAllowlist(cidrs ...string) Option {
+	return serverOptionFunc(func(c *serverConfig) error {
+		if len(cidrs) == 0 {
+			return errors.New("admin allowlist must not be empty")
+		}
+
+		nets, err := ParseCIDRs(cidrs...)
+		if err != nil {
+			return err
+		}
+
+		c.adminAllowed = append(c.adminAllowed, nets...)
+
+		return nil
+	})
+}
+
+// WithTrustedProxies sets the proxies whose X-Forwarded-For header is used to
+// find the client address. The default is to trust no proxies.
+func WithTrustedProxies(cidrs ...string) Option {
+	return serverOptionFunc(func(c *serverConfig) error {
+		nets, err := ParseCIDRs(cidrs...)
+		if err != nil {
+			return err
+		}
+
+		c.trustedProxies = append(c.trustedProxies, nets...)
+
+		return nil
+	})
+}
+
+// WithBasicAuth requires HTTP basic auth for requests matching the patterns,
+// which follow the same rules as AddMiddlewareFor, on both the main and admin
+// listeners. See BasicAuth. The default is no authentication.
+func WithBasicAuth(username string, passwordHash string, patterns ...string) Option {
+	return serverOptionFunc(func(c *serverConfig) error {
+		if username == "" {
+			return errors.New("basic auth username must not be empty")
+		}
+
+		if len(patterns) == 0 {
+			return errors.New("basic auth paths must not be empty")
+		}
+
+		middleware, err := BasicAuth(username, passwordHash)
+		if err != nil {
+			return err
+		}
+
+		c.basicAuth = middleware
+		c.basicAuthPaths = patterns
+
+		return nil
+	})
+}
+
+// WithRateLimit limits each client to limit requests per second, with bursts of
+// up to burst requests. See RateLimit. The default is no limit.
+func WithRateLimit(limit float64, burst int) Option {
+	return serverOptionFunc(func(c *serverConfig) error {
+		if limit <= 0 {
+			return errors.New("rate limit must be positive")
+		}
+
+		if burst < 1 {
+			return errors.New("rate limit burst must be at least 1")
+		}
+
+		c.rateLimit = limit
+		c.rateLimitBurst = burst
+
+		return nil
+	})
+}
+
+// WithPprof enables or disables the net/http/pprof handlers under
+// /debug/pprof/. They are served on the admin listener when one is configured,
+// which is strongly recommended. The default is disabled.
+func WithPprof(enabled bool) Option {
+	return serverOptionFunc(func(c *serverConfig) error {
+		c.pprof = enabled
+
+		return nil
+	})
+}
+
 func WithConfig(c Config) Option {
 	options := serverOptions{
 		WithServerAddress("tcp", c.Address),
+		WithGzip(c.Gzip),
+		WithGzipLevel(c.GzipLevel),
+		WithHTTP2(c.H2C),
+		WithMaxConcurrentRequests(c.MaxConcurrentRequests),
+		WithMaxRequestBytes(c.MaxRequestBytes),
+		WithTelemetry(c.Telemetry),
+		WithPprof(c.Pprof),
+		WithPublicAdmin(c.PublicAdmin),
+		WithTimeouts(Timeouts{
+			ReadHeader: c.ReadHeaderTimeout,
+			Read:       c.ReadTimeout,
+			Write:      c.WriteTimeout,
+			Idle:       c.IdleTimeout,
+		}),
+	}
+
+	if c.ShutdownTimeout != 0 {
+		options = append(options, WithShutdownTimeout(c.ShutdownTimeout))
+	}
+
+	if c.TLSCertFile != "" || c.TLSKeyFile != "" {
+		options = append(options, WithTLS(c.TLSCertFile, c.TLSKeyFile))
+	}
+
+	if len(c.AllowedOrigins) > 0 {
+		options = append(options, WithAllowedOrigins(c.AllowedOrigins...))
+	}
+
+	if c.AdminAddress != "" {
+		options = append(options, WithAdminAddress(c.AdminAddress))
+	}
+
+	if len(c.AdminAllowedCIDRs) > 0 {
+		options = append(options, WithAdminAllowlist(c.AdminAllowedCIDRs...))
+	}
+
+	if len(c.TrustedProxies) > 0 {
+		options = append(options, WithTrustedProxies(c.TrustedProxies...))
+	}
+
+	if c.RateLimit != 0 {
+		options = append(options, WithRateLimit(c.RateLimit, c.RateLimitBurst))
+	}
+
+	if c.BasicAuthUsername != "" || c.BasicAuthPasswordHash != "" {
+		options = append(options, WithBasicAuth(c.BasicAuthUsername, c.BasicAuthPasswordHash, c.BasicAuthPaths...))
 	}
 
 	return options
@@ -92,8 +521,13 @@ func WithConfig(c Config) Option {
 // New creates a new HTTP server
 func New(options ...Option) (*Server, error) {
 	cfg := serverConfig{
-		network: "tcp",
-		address: "127.0.0.1:0",
+		network:         "tcp",
+		address:         "127.0.0.1:0",
+		shutdownTimeout: time.Second * 10,
+		gzip:            true,
+		gzipLevel:       gzip.DefaultCompression,
+		h2c:             true,
+		maxRequestBytes: 1 << 20,
 	}
 
 	for _, o := range options {
@@ -106,15 +540,118 @@ func New(options ...Option) (*Server, error) {
 		config:     &cfg,
 		mux:        http.NewServeMux(),
 		reflection: reflection.NewServer(),
+		addr:       newBoundAddress(),
+		adminAddr:  newBoundAddress(),
+		tracker:    newTracker(),
+		stop:       make(chan struct{}),
+		running:    make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+
+	if cfg.adminAddress != "" || cfg.adminListener != nil {
+		s.admin = http.NewServeMux()
+	}
+
+	if err := s.tracker.register(); err != nil {
+		return nil, fmt.Errorf("failed to create HTTP server %w", err)
+	}
+
+	if cfg.listener != nil {
+		s.addr.set(cfg.listener)
+	}
+
+	if cfg.adminListener != nil {
+		s.adminAddr.set(cfg.adminListener)
 	}
 
 	s.RegisterService(s.reflection)
 
-	s.AddMiddleware(func(next http.Handler) http.Handler {
-		return h2c.NewHandler(next, &http2.Server{})
-	})
+	s.handleHealth("/healthz", http.HandlerFunc(serveHealthz))
+	s.handleHealth("/readyz", http.HandlerFunc(s.serveReadyz))
+	s.handleHealth("/version", http.HandlerFunc(serveVersion))
+
+	if cfg.pprof {
+		s.handlePprof()
+	}
+
+	// TLS negotiates HTTP/2 itself, so h2c is only needed for plaintext
+	if cfg.tls == nil && cfg.h2c {
+		s.use(func(next http.Handler) http.Handler {
+			return h2c.NewHandler(next, &http2.Server{})
+		})
+	}
+
+	// Recovery wraps the rest of the middleware, so a panic in any of it is
+	// recovered. It is inside h2c, which runs each HTTP/2 stream separately,
+	// and inside the request id and access log, so a panic is logged with
+	// its request id and the 500 is logged. This applies to both listeners.
+	if cfg.logger != nil {
+		s.AddMiddleware(logging.Middleware(cfg.logger))
+	}
+
+	s.AddMiddleware(RequestID)
+	s.AddMiddleware(AccessLog)
+	s.AddMiddleware(Recovery)
+
+	// requests are instrumented and counted inside h2c so that each HTTP/2
+	// stream is seen.
+	if cfg.telemetry {
+		s.use(s.telemetry)
+	}
+
+	s.use(s.tracker.middleware)
+
+	if cfg.rateLimit > 0 {
+		s.use(RateLimit(cfg.rateLimit, cfg.rateLimitBurst, cfg.trustedProxies))
+	}
+
+	if cfg.maxConcurrent > 0 {
+		s.use(ConcurrencyLimit(cfg.maxConcurrent))
+	}
+
+	if cfg.maxRequestBytes > 0 {
+		s.use(MaxBytes(cfg.maxRequestBytes))
+	}
 
-	s.AddMiddleware(gziphandler.GzipHandler)
+	if cfg.gzip {
+		// responses smaller than the minimum, such as most protobuf
+		// responses, are passed through uncompressed.
+		gz, err := gziphandler.GzipHandlerWithOpts(
+			gziphandler.CompressionLevel(cfg.gzipLevel),
+			gziphandler.MinSize(gziphandler.DefaultMinSize),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gzip handler %w", err)
+		}
+
+		s.use(func(next http.Handler) http.Handler {
+			compressed := gz(next)
+
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// gzip buffers writes, so streams would not be flushed
+				for _, pattern := range s.streams {
+					if pathMatch(pattern, r.URL.Path) {
+						next.ServeHTTP(w, r)
+						return
+					}
+				}
+
+				compressed.ServeHTTP(w, r)
+			})
+		})
+	}
+
+	// preflight requests are answered here, inside h2c and gzip, so they
+	// never reach middleware added by callers.
+	if len(cfg.allowedOrigins) > 0 {
+		s.use(CORS(cfg.allowedOrigins))
+	}
+
+	// after CORS, as preflight requests do not include credentials. Paths on
+	// the admin listener are protected too.
+	for _, pattern := range cfg.basicAuthPaths {
+		s.AddMiddlewareFor(pattern, cfg.basicAuth)
+	}
 
 	return s, nil
 }
@@ -124,27 +661,143 @@ func (s *Server) Handle(pattern string, handler http.Handler) {
 	s.mux.Handle(pattern, handler)
 }
 
+// HandleStream adds a handler, such as server-sent events, whose responses are
+// flushed as they are written. They are not compressed, which would buffer
+// them. Long lived streams should end when a function added with
+// RegisterOnShutdown is called.
+func (s *Server) HandleStream(pattern string, handler http.Handler) {
+	s.streams = append(s.streams, pattern)
+	s.Handle(pattern, handler)
+}
+
+// RegisterOnShutdown adds a function that is called when a graceful shutdown
+// begins, before waiting for in-flight requests to finish.
+func (s *Server) RegisterOnShutdown(f func()) {
+	s.onShutdown = append(s.onShutdown, f)
+}
+
+// HandleAdmin adds a handler for an operational endpoint, such as metrics. It
+// is served on the admin listener if one is configured. Otherwise it is the
+// same as Handle if WithPublicAdmin is enabled, and is not served if not. The
+// admin allowlist applies in either case.
+func (s *Server) HandleAdmin(pattern string, handler http.Handler) {
+	if !s.AdminEnabled() {
+		return
+	}
+
+	s.handleHealth(pattern, handler)
+}
+
+// AdminEnabled returns true if the endpoints added with HandleAdmin are
+// served.
+func (s *Server) AdminEnabled() bool {
+	return s.admin != nil || s.config.publicAdmin
+}
+
+// handleHealth is like HandleAdmin, but always serves handler, as health
+// checks are needed even without an admin listener.
+func (s *Server) handleHealth(pattern string, handler http.Handler) {
+	if len(s.config.adminAllowed) > 0 {
+		handler = Allowlist(s.config.adminAllowed, s.config.trustedProxies)(handler)
+	}
+
+	if s.admin == nil {
+		s.Handle(pattern, handler)
+		return
+	}
+
+	s.admin.Handle(pattern, handler)
+}
+
+// Run serves until ctx is cancelled or Shutdown is called, then gracefully
+// shuts down. It may only be called once.
+//
+// Run returns nil after a clean shutdown. If in-flight requests do not finish
+// within the shutdown timeout, their connections are closed and the returned
+// error wraps context.DeadlineExceeded.
 func (s *Server) Run(ctx context.Context) error {
-	listener, err := net.Listen(s.config.network, s.config.address)
+	first := false
+	s.runOnce.Do(func() {
+		first = true
+		close(s.running)
+	})
+
+	if !first {
+		return errors.New("HTTP server is already running")
+	}
+
+	err := s.run(ctx)
+
+	s.runErr = err
+	close(s.done)
+
+	return err
+}
+
+// Shutdown gracefully stops the server, as if the context passed to Run was
+// cancelled, and waits for Run to return. It returns the error from Run, or
+// ctx's error if it is done first. If Run has not been called, Shutdown
+// returns immediately and a later Run returns without serving.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	select {
+	case <-s.running:
+	default:
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.done:
+		return s.runErr
+	}
+}
+
+func (s *Server) run(ctx context.Context) error {
+	select {
+	case <-s.stop:
+		return nil
+	default:
+	}
+
+	listener, err := s.listen(s.config.listener, s.config.address, s.addr)
 	if err != nil {
-		return fmt.Errorf(
-			"failed to listen %q %q %w",
-			s.config.network,
-			s.config.address,
-			err,
-		)
+		return err
 	}
 
-	s.listener.Store(listener)
+	var adminListener net.Listener
+	if s.admin != nil {
+		adminListener, err = s.listen(s.config.adminListener, s.config.adminAddress, s.adminAddr)
+		if err != nil {
+			_ = listener.Close()
+			return err
+		}
+	}
 
 	svr := &http.Server{
-		Handler: s.chain.Then(s.mux),
+		Handler:           s.chain.Then(s.mux),
+		TLSConfig:         s.config.tls,
+		ReadHeaderTimeout: s.config.timeouts.ReadHeader,
+		ReadTimeout:       s.config.timeouts.Read,
+		WriteTimeout:      s.config.timeouts.Write,
+		IdleTimeout:       s.config.timeouts.Idle,
+		ConnState:         s.tracker.connState,
 	}
 
 	eg, ctx := errgroup.WithContext(ctx)
 
 	eg.Go(func() error {
-		if err := svr.Serve(listener); err != nil {
+		serve := svr.Serve
+		if svr.TLSConfig != nil {
+			serve = func(l net.Listener) error {
+				// certificates are already loaded in TLSConfig
+				return svr.ServeTLS(l, "", "")
+			}
+		}
+
+		if err := serve(listener); err != nil {
 			if err != http.ErrServerClosed {
 				return err
 			}
@@ -153,45 +806,195 @@ func (s *Server) Run(ctx context.Context) error {
 		return nil
 	})
 
+	stopped := make(chan struct{})
+
 	eg.Go(func() error {
-		<-ctx.Done()
-		// allow adjusting timeout?
-		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), time.Second*10)
+		defer close(stopped)
+
+		select {
+		case <-ctx.Done():
+		case <-s.stop:
+		}
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), s.config.shutdownTimeout)
 		defer shutdownCancel()
 
-		_ = svr.Shutdown(shutdownCtx)
+		for _, f := range s.onShutdown {
+			f()
+		}
+
+		// new requests are refused while in-flight requests, including
+		// h2c streams that Shutdown does not track, are given time to finish.
+		svr.SetKeepAlivesEnabled(false)
+		drainErr := s.tracker.drain(shutdownCtx)
+
+		if err := svr.Shutdown(shutdownCtx); err != nil {
+			// connections still open after the timeout are force closed.
+			_ = svr.Close()
+			return fmt.Errorf("failed to gracefully shutdown HTTP server %w", err)
+		}
+
+		if drainErr != nil {
+			return fmt.Errorf("failed to drain HTTP server %w", drainErr)
+		}
 
 		return nil
 	})
 
+	if s.admin != nil {
+		s.runAdmin(eg, adminListener, stopped)
+	}
+
 	return eg.Wait()
 }
 
-// TODO: allow setting a matcher on middleware?
+// runAdmin serves the admin endpoints until the main server has stopped, so
+// health checks report draining while in-flight requests finish.
+func (s *Server) runAdmin(eg *errgroup.Group, listener net.Listener, stopped <-chan struct{}) {
+	svr := &http.Server{
+		Handler:           s.adminChain.Then(s.admin),
+		ReadHeaderTimeout: s.config.timeouts.ReadHeader,
+		ReadTimeout:       s.config.timeouts.Read,
+		WriteTimeout:      s.config.timeouts.Write,
+		IdleTimeout:       s.config.timeouts.Idle,
+	}
+
+	eg.Go(func() error {
+		if err := svr.Serve(listener); err != nil {
+			if err != http.ErrServerClosed {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	eg.Go(func() error {
+		<-stopped
+
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), s.config.shutdownTimeout)
+		defer shutdownCancel()
+
+		if err := svr.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("failed to gracefully shutdown admin HTTP server %w", err)
+		}
+
+		return nil
+	})
+}
+
+// listen returns l, or a new listener on address if l is nil.
+func (s *Server) listen(l net.Listener, address string, bound *boundAddress) (net.Listener, error) {
+	if l != nil {
+		return l, nil
+	}
+
+	l, err := net.Listen(s.config.network, address)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to listen %q %q %w",
+			s.config.network,
+			address,
+			err,
+		)
+	}
+
+	bound.set(l)
+
+	return l, nil
+}
+
+// AddMiddleware adds middleware that applies to every request, including those
+// to the admin listener. Middleware is run in the order it is added.
 func (s *Server) AddMiddleware(middleware func(http.Handler) http.Handler) {
+	s.use(middleware)
+	s.adminChain = s.adminChain.Append(middleware)
+}
+
+// use adds middleware to the main listener only, such as middleware that
+// limits or counts API requests.
+func (s *Server) use(middleware func(http.Handler) http.Handler) {
 	s.chain = s.chain.Append(middleware)
 }
 
+// AddMiddlewareFor adds middleware that only applies to requests whose path
+// matches pattern. Patterns follow the http.ServeMux convention: a pattern
+// ending in a slash matches every path with that prefix, otherwise the path
+// must match exactly.
+func (s *Server) AddMiddlewareFor(pattern string, middleware func(http.Handler) http.Handler) {
+	s.AddMiddleware(forPattern(pattern, middleware))
+}
+
+func forPattern(pattern string, middleware func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		wrapped := middleware(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if pathMatch(pattern, r.URL.Path) {
+				wrapped.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func pathMatch(pattern string, path string) bool {
+	if strings.HasSuffix(pattern, "/") {
+		return strings.HasPrefix(path, pattern)
+	}
+
+	return path == pattern
+}
+
+// InFlight returns the number of requests currently being handled.
+func (s *Server) InFlight() int64 {
+	return s.tracker.requests()
+}
+
 // WaitForAddress waits until an address is assigned. Useful when generating
 // a listening socket.
 func (s *Server) WaitForAddress(ctx context.Context) (net.Addr, error) {
-	// so cheesy
-	t := time.NewTicker(time.Millisecond * 100)
-	defer t.Stop()
+	return s.addr.wait(ctx)
+}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-t.C:
-			raw := s.listener.Load()
-			if raw != nil {
-				l, ok := raw.(net.Listener)
-				if ok {
-					return l.Addr(), nil
-				}
-			}
-		}
+// WaitForAdminAddress is like WaitForAddress for the admin listener. It
+// returns an error if there is no admin listener.
+func (s *Server) WaitForAdminAddress(ctx context.Context) (net.Addr, error) {
+	if s.admin == nil {
+		return nil, errors.New("admin listener is not configured")
+	}
+
+	return s.adminAddr.wait(ctx)
+}
+
+// boundAddress records a listener once it is known.
+type boundAddress struct {
+	once     sync.Once
+	ready    chan struct{}
+	listener net.Listener
+}
+
+func newBoundAddress() *boundAddress {
+	return &boundAddress{
+		ready: make(chan struct{}),
+	}
+}
+
+// set records the listener and wakes any callers of wait.
+func (b *boundAddress) set(l net.Listener) {
+	b.once.Do(func() {
+		b.listener = l
+		close(b.ready)
+	})
+}
+
+func (b *boundAddress) wait(ctx context.Context) (net.Addr, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-b.ready:
+		return b.listener.Addr(), nil
 	}
 }
 
@@ -205,3 +1008,10 @@ func (s *Server) RegisterService(t TwirpServer) {
 	s.mux.Handle(t.PathPrefix(), t)
 	s.reflection.RegisterService(t)
 }
+
+// RegisterServices registers each of the twirp services.
+func (s *Server) RegisterServices(services ...TwirpServer) {
+	for _, t := range services {
+		s.RegisterService(t)
+	}
+}
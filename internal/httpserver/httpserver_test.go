@@ -0,0 +1,375 @@
+package httpserver_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/bakins/twirp-todo-example/internal/httpserver"
+)
+
+// startServer runs svr until the test completes and returns its address.
+func startServer(t *testing.T, svr *httpserver.Server) net.Addr {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go func() {
+		_ = svr.Run(ctx)
+	}()
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, time.Second*10)
+	defer waitCancel()
+
+	addr, err := svr.WaitForAddress(waitCtx)
+	require.NoError(t, err)
+
+	return addr
+}
+
+func TestAddMiddlewareFor(t *testing.T) {
+	svr, err := httpserver.New()
+	require.NoError(t, err)
+
+	svr.AddMiddlewareFor("/twirp/", func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Matched", "true")
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	})
+
+	svr.Handle("/twirp/", handler)
+	svr.Handle("/metrics", handler)
+
+	addr := startServer(t, svr)
+
+	tests := map[string]string{
+		"/twirp/bakins.todo.v1.TodoService/ListTasks": "true",
+		"/metrics": "",
+	}
+
+	for path, expected := range tests {
+		resp, err := http.Get("http://" + addr.String() + path)
+		require.NoError(t, err)
+
+		_ = resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode, path)
+		require.Equal(t, expected, resp.Header.Get("X-Matched"), path)
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+
+	svr, err := httpserver.New(httpserver.WithLogger(zap.New(core)))
+	require.NoError(t, err)
+
+	// middleware added by callers is inside recovery
+	svr.AddMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/middleware" {
+				panic("middleware")
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	svr.Handle("/panic", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("oops")
+	}))
+
+	addr := startServer(t, svr)
+
+	for _, path := range []string{"/panic", "/middleware"} {
+		resp, err := http.Get("http://" + addr.String() + path)
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusInternalServerError, resp.StatusCode, path)
+
+		var body struct {
+			Code string `json:"code"`
+		}
+
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		require.Equal(t, "internal", body.Code)
+
+		_ = resp.Body.Close()
+
+		id := resp.Header.Get(httpserver.RequestIDHeader)
+		require.NotEmpty(t, id)
+
+		// the panic and the request are both logged with the request id
+		for _, msg := range []string{"panic while handling request", "request"} {
+			entries := logs.FilterMessage(msg).FilterField(zap.String("requestId", id)).All()
+			require.Len(t, entries, 1, msg)
+		}
+	}
+}
+
+func TestWithListener(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	svr, err := httpserver.New(httpserver.WithListener(l))
+	require.NoError(t, err)
+
+	svr.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+
+	// the address is known before Run is called
+	addr, err := svr.WaitForAddress(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, l.Addr().String(), addr.String())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go func() {
+		_ = svr.Run(ctx)
+	}()
+
+	resp, err := http.Get("http://" + addr.String() + "/")
+	require.NoError(t, err)
+
+	_ = resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestAdminListener(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	admin, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	svr, err := httpserver.New(
+		httpserver.WithListener(l),
+		httpserver.WithAdminListener(admin),
+	)
+	require.NoError(t, err)
+
+	svr.HandleAdmin("/metrics", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+
+	// middleware applies to both listeners
+	svr.AddMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Middleware", "yes")
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	addr := startServer(t, svr)
+
+	adminAddr, err := svr.WaitForAdminAddress(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, admin.Addr().String(), adminAddr.String())
+
+	tests := []struct {
+		addr   net.Addr
+		path   string
+		status int
+	}{
+		{addr: adminAddr, path: "/healthz", status: http.StatusOK},
+		{addr: adminAddr, path: "/metrics", status: http.StatusOK},
+		{addr: addr, path: "/healthz", status: http.StatusNotFound},
+		{addr: addr, path: "/metrics", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		resp, err := http.Get("http://" + tt.addr.String() + tt.path)
+		require.NoError(t, err)
+
+		_ = resp.Body.Close()
+
+		require.Equal(t, tt.status, resp.StatusCode, tt.path)
+		require.Equal(t, "yes", resp.Header.Get("X-Middleware"), tt.path)
+	}
+}
+
+func TestPublicAdmin(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		svr, err := httpserver.New(httpserver.WithPublicAdmin(enabled))
+		require.NoError(t, err)
+
+		require.Equal(t, enabled, svr.AdminEnabled())
+
+		svr.HandleAdmin("/metrics", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "ok")
+		}))
+
+		addr := startServer(t, svr)
+
+		expected := http.StatusNotFound
+		if enabled {
+			expected = http.StatusOK
+		}
+
+		for path, status := range map[string]int{"/healthz": http.StatusOK, "/metrics": expected} {
+			resp, err := http.Get("http://" + addr.String() + path)
+			require.NoError(t, err)
+
+			_ = resp.Body.Close()
+
+			require.Equal(t, status, resp.StatusCode, path)
+		}
+	}
+}
+
+func TestPprof(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		svr, err := httpserver.New(httpserver.WithPprof(enabled), httpserver.WithPublicAdmin(true))
+		require.NoError(t, err)
+
+		addr := startServer(t, svr)
+
+		resp, err := http.Get("http://" + addr.String() + "/debug/pprof/goroutine?debug=1")
+		require.NoError(t, err)
+
+		_ = resp.Body.Close()
+
+		expected := http.StatusNotFound
+		if enabled {
+			expected = http.StatusOK
+		}
+
+		require.Equal(t, expected, resp.StatusCode)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	svr, err := httpserver.New()
+	require.NoError(t, err)
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- svr.Run(context.Background())
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	addr, err := svr.WaitForAddress(ctx)
+	require.NoError(t, err)
+
+	resp, err := http.Get("http://" + addr.String() + "/healthz")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	require.NoError(t, svr.Shutdown(ctx))
+	require.NoError(t, <-errs)
+
+	// shutting down before running is safe, and Run returns immediately
+	svr, err = httpserver.New()
+	require.NoError(t, err)
+
+	require.NoError(t, svr.Shutdown(ctx))
+	require.NoError(t, svr.Run(ctx))
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	svr, err := httpserver.New(httpserver.WithShutdownTimeout(time.Millisecond * 100))
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+
+	svr.Handle("/slow", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- svr.Run(context.Background())
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	addr, err := svr.WaitForAddress(ctx)
+	require.NoError(t, err)
+
+	go func() {
+		resp, err := http.Get("http://" + addr.String() + "/slow")
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+	}()
+
+	<-started
+
+	err = svr.Shutdown(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorIs(t, <-errs, context.DeadlineExceeded)
+}
+
+func TestHandleStream(t *testing.T) {
+	svr, err := httpserver.New(httpserver.WithShutdownTimeout(time.Second * 5))
+	require.NoError(t, err)
+
+	stop := make(chan struct{})
+	svr.RegisterOnShutdown(func() { close(stop) })
+
+	svr.HandleStream("/stream", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "data: first\n\n")
+		w.(http.Flusher).Flush()
+
+		select {
+		case <-stop:
+		case <-r.Context().Done():
+		}
+	}))
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- svr.Run(context.Background())
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	addr, err := svr.WaitForAddress(ctx)
+	require.NoError(t, err)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+addr.String()+"/stream", nil)
+	require.NoError(t, err)
+
+	// streams are not compressed, as gzip would buffer the small event
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	require.Empty(t, resp.Header.Get("Content-Encoding"))
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "data: first\n", line)
+
+	// the stream is ended by the shutdown hook, so shutdown is graceful
+	require.NoError(t, svr.Shutdown(ctx))
+	require.NoError(t, <-errs)
+}
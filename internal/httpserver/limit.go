@@ -0,0 +1,59 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/twitchtv/twirp"
+)
+
+// ConcurrencyLimit returns middleware that allows at most limit requests to be
+// handled at once. Requests over the limit are rejected immediately with an
+// unavailable Twirp error, which is a 503, rather than queued. Health checks
+// are not limited.
+func ConcurrencyLimit(limit int) func(http.Handler) http.Handler {
+	sem := make(chan struct{}, limit)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/healthz" || r.URL.Path == "/readyz" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			select {
+			case sem <- struct{}{}:
+			default:
+				_ = twirp.WriteError(w, twirp.NewError(twirp.Unavailable, "too many concurrent requests"))
+				return
+			}
+
+			defer func() { <-sem }()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// MaxBytes returns middleware that limits request bodies to limit bytes.
+// Requests that declare a larger Content-Length are rejected with a malformed
+// Twirp error before the body is read. Otherwise reads past the limit fail, and
+// Twirp services also respond with a malformed error.
+func MaxBytes(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				err := twirp.NewError(twirp.Malformed, "request body too large").
+					WithMeta("max_bytes", strconv.FormatInt(limit, 10))
+
+				w.Header().Set("Connection", "close")
+				_ = twirp.WriteError(w, err)
+				return
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
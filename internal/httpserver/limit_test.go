@@ -0,0 +1,118 @@
+package httpserver_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/twirp-todo-example/internal/httpserver"
+)
+
+func TestConcurrencyLimit(t *testing.T) {
+	svr, err := httpserver.New(httpserver.WithMaxConcurrentRequests(1))
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	svr.Handle("/slow", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		_, _ = io.WriteString(w, "ok")
+	}))
+
+	addr := startServer(t, svr)
+	base := "http://" + addr.String()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		resp, err := http.Get(base + "/slow")
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+	}()
+
+	<-started
+
+	resp, err := http.Get(base + "/slow")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	// health checks are not limited
+	resp, err = http.Get(base + "/healthz")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	close(release)
+	<-done
+}
+
+func TestMaxBytes(t *testing.T) {
+	svr, err := httpserver.New(httpserver.WithMaxRequestBytes(10))
+	require.NoError(t, err)
+
+	svr.Handle("/echo", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		_, _ = io.WriteString(w, "ok")
+	}))
+
+	addr := startServer(t, svr)
+	base := "http://" + addr.String()
+
+	resp, err := http.Post(base+"/echo", "text/plain", strings.NewReader("small"))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// rejected using the Content-Length
+	resp, err = http.Post(base+"/echo", "text/plain", strings.NewReader("this is too large"))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// no Content-Length, so the read fails
+	body := io.MultiReader(strings.NewReader("this is "), strings.NewReader("too large"))
+	resp, err = http.Post(base+"/echo", "text/plain", body)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+}
+
+func TestRateLimit(t *testing.T) {
+	svr, err := httpserver.New(httpserver.WithRateLimit(0.1, 1))
+	require.NoError(t, err)
+
+	svr.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+
+	addr := startServer(t, svr)
+	base := "http://" + addr.String()
+
+	resp, err := http.Get(base + "/")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Get(base + "/")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	require.NotEmpty(t, resp.Header.Get("Retry-After"))
+
+	// health checks are not limited
+	resp, err = http.Get(base + "/healthz")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
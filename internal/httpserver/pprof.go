@@ -0,0 +1,17 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/pprof"
+)
+
+// handlePprof adds the net/http/pprof handlers. Named profiles, such as heap
+// and goroutine, are served by the index. Profiles longer than any write
+// timeout are cut short, so the seconds parameter should be set below it.
+func (s *Server) handlePprof() {
+	s.HandleAdmin("/debug/pprof/", http.HandlerFunc(pprof.Index))
+	s.HandleAdmin("/debug/pprof/cmdline", http.HandlerFunc(pprof.Cmdline))
+	s.HandleAdmin("/debug/pprof/profile", http.HandlerFunc(pprof.Profile))
+	s.HandleAdmin("/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
+	s.HandleAdmin("/debug/pprof/trace", http.HandlerFunc(pprof.Trace))
+}
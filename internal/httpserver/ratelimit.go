@@ -0,0 +1,99 @@
+package httpserver
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/twitchtv/twirp"
+	"golang.org/x/time/rate"
+)
+
+// rateLimitIdle is how long a client's limiter is kept after its last request.
+const rateLimitIdle = time.Minute * 5
+
+type rateLimiter struct {
+	limit          rate.Limit
+	burst          int
+	trustedProxies []*net.IPNet
+
+	lock      sync.Mutex
+	clients   map[string]*clientLimiter
+	lastSweep time.Time
+}
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// RateLimit returns middleware that limits each client to limit requests per
+// second, with bursts of up to burst requests. Clients are identified by
+// address, using ClientIP. Requests over the limit are rejected with a
+// resource_exhausted Twirp error, which is a 429, and a Retry-After header.
+// Health checks are not limited.
+func RateLimit(limit float64, burst int, trustedProxies []*net.IPNet) func(http.Handler) http.Handler {
+	l := &rateLimiter{
+		limit:          rate.Limit(limit),
+		burst:          burst,
+		trustedProxies: trustedProxies,
+		clients:        map[string]*clientLimiter{},
+		lastSweep:      time.Now(),
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/healthz" || r.URL.Path == "/readyz" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			reservation := l.reserve(ClientIP(r, l.trustedProxies).String())
+
+			if delay := reservation.Delay(); delay > 0 {
+				reservation.Cancel()
+
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+				_ = twirp.WriteError(w, twirp.NewError(twirp.ResourceExhausted, "rate limit exceeded"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (l *rateLimiter) reserve(client string) *rate.Reservation {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+
+	// idle clients are removed as requests arrive, rather than by a
+	// goroutine, so nothing needs to be stopped.
+	if now.Sub(l.lastSweep) > rateLimitIdle {
+		for key, c := range l.clients {
+			if now.Sub(c.lastSeen) > rateLimitIdle {
+				delete(l.clients, key)
+			}
+		}
+
+		l.lastSweep = now
+	}
+
+	c, ok := l.clients[client]
+	if !ok {
+		c = &clientLimiter{
+			limiter: rate.NewLimiter(l.limit, l.burst),
+		}
+
+		l.clients[client] = c
+	}
+
+	c.lastSeen = now
+
+	return c.limiter.ReserveN(now, 1)
+}
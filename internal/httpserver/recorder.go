@@ -0,0 +1,73 @@
+package httpserver
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// ResponseRecorder wraps an http.ResponseWriter and records the status code
+// and number of bytes written, for use by middleware. Flush and Hijack are
+// passed through, so it may wrap the writer used for h2c upgrades.
+type ResponseRecorder struct {
+	http.ResponseWriter
+	// StatusCode is http.StatusOK if WriteHeader was not called.
+	StatusCode   int
+	BytesWritten int64
+	wroteHeader  bool
+}
+
+var (
+	_ http.Flusher  = &ResponseRecorder{}
+	_ http.Hijacker = &ResponseRecorder{}
+)
+
+func NewResponseRecorder(w http.ResponseWriter) *ResponseRecorder {
+	return &ResponseRecorder{
+		ResponseWriter: w,
+		StatusCode:     http.StatusOK,
+	}
+}
+
+func (r *ResponseRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.StatusCode = code
+		r.wroteHeader = true
+	}
+
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *ResponseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+
+	n, err := r.ResponseWriter.Write(b)
+	r.BytesWritten += int64(n)
+
+	return n, err
+}
+
+func (r *ResponseRecorder) Flush() {
+	f, ok := r.ResponseWriter.(http.Flusher)
+	if !ok {
+		return
+	}
+
+	r.wroteHeader = true
+	f.Flush()
+}
+
+func (r *ResponseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+
+	return h.Hijack()
+}
+
+// Unwrap returns the wrapped writer, for use by http.ResponseController.
+func (r *ResponseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
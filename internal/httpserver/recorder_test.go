@@ -0,0 +1,34 @@
+package httpserver_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/twirp-todo-example/internal/httpserver"
+)
+
+func TestResponseRecorder(t *testing.T) {
+	// status defaults to 200
+	rec := httpserver.NewResponseRecorder(httptest.NewRecorder())
+
+	_, err := io.WriteString(rec, "hello")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.StatusCode)
+	require.Equal(t, int64(5), rec.BytesWritten)
+
+	// only the first status is recorded
+	rec = httpserver.NewResponseRecorder(httptest.NewRecorder())
+	rec.WriteHeader(http.StatusNotFound)
+	rec.WriteHeader(http.StatusInternalServerError)
+	require.Equal(t, http.StatusNotFound, rec.StatusCode)
+
+	// httptest.ResponseRecorder cannot be hijacked
+	_, _, err = rec.Hijack()
+	require.Error(t, err)
+
+	rec.Flush()
+}
@@ -0,0 +1,42 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/twitchtv/twirp"
+	"go.uber.org/zap"
+
+	"github.com/bakins/twirp-todo-example/internal/logging"
+)
+
+// Recovery is middleware that recovers from panics in later handlers. The
+// panic is logged using the logger in the request context and a Twirp internal
+// error is returned to the client.
+//
+// http.ErrAbortHandler is re-raised so the server can abort the response.
+func Recovery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+
+			logging.Error(r.Context(), "panic while handling request",
+				zap.String("panic", fmt.Sprint(p)),
+				zap.String("stack", string(debug.Stack())),
+				zap.String("path", r.URL.Path),
+			)
+
+			_ = twirp.WriteError(w, twirp.InternalError("internal server error"))
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
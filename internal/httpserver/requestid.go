@@ -0,0 +1,59 @@
+package httpserver
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+
+	"github.com/bakins/twirp-todo-example/internal/logging"
+)
+
+// RequestIDHeader is the header used to propagate request ids.
+const RequestIDHeader = "X-Request-Id"
+
+const maxRequestIDLength = 128
+
+// RequestID is middleware that assigns an id to each request. The id is taken
+// from the X-Request-Id header when present, otherwise one is generated. The id
+// is added to the response headers and to the logger in the request context,
+// so it should be added after logging.Middleware.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
+
+// validRequestID guards against untrusted ids polluting logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// newRequestID returns a random (version 4) UUID.
+func newRequestID() string {
+	var b [16]byte
+
+	// crypto/rand does not fail on supported platforms
+	_, _ = rand.Read(b[:])
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
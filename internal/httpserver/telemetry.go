@@ -0,0 +1,43 @@
+package httpserver
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+)
+
+// telemetry is middleware that creates a span and records metrics for every
+// request, including those not handled by Twirp. The route attribute is the
+// pattern the request matched rather than the path, which keeps the number
+// of distinct metric series bounded.
+func (s *Server) telemetry(next http.Handler) http.Handler {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route := s.route(r); route != "" {
+			labeler, _ := otelhttp.LabelerFromContext(r.Context())
+			labeler.Add(semconv.HTTPRouteKey.String(route))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+
+	return otelhttp.NewHandler(
+		handler,
+		"http.server",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			if route := s.route(r); route != "" {
+				return r.Method + " " + route
+			}
+
+			return r.Method
+		}),
+	)
+}
+
+// route returns the pattern registered for the request, or an empty string
+// if there is none.
+func (s *Server) route(r *http.Request) string {
+	_, pattern := s.mux.Handler(r)
+
+	return pattern
+}
@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/twitchtv/twirp"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/bakins/twirp-todo-example/internal/metadata"
+	"github.com/bakins/twirp-todo-example/internal/stackdriver"
+)
+
+// Interceptor returns a twirp interceptor that logs the outcome of each RPC
+// using the logger in the context. The logger passed to the handler has the
+// service and method fields added.
+func Interceptor() twirp.Interceptor {
+	return func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			pkg, _ := twirp.PackageName(ctx)
+			service, _ := twirp.ServiceName(ctx)
+			method, _ := twirp.MethodName(ctx)
+
+			ctx = AddFields(ctx,
+				zap.String("rpc.package", pkg),
+				zap.String("rpc.service", service),
+				zap.String("rpc.method", method),
+			)
+
+			ctx = WithTrace(ctx)
+
+			start := time.Now()
+
+			resp, err := next(ctx, req)
+
+			duration := zap.Duration("duration", time.Since(start))
+
+			if err == nil {
+				Info(ctx, "rpc", duration)
+				return resp, err
+			}
+
+			code := errorCode(err)
+			fields := []zap.Field{duration, zap.String("code", string(code)), zap.Error(err)}
+
+			// client errors, such as not_found, are expected
+			if twirp.ServerHTTPStatusFromErrorCode(code) >= http.StatusInternalServerError {
+				Error(ctx, "rpc", fields...)
+			} else {
+				Info(ctx, "rpc", fields...)
+			}
+
+			return resp, err
+		}
+	}
+}
+
+// WithTrace adds the active trace and span ids, and whether the trace is
+// sampled, to the context logger, so log lines can be viewed alongside the
+// trace.
+func WithTrace(ctx context.Context) context.Context {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return ctx
+	}
+
+	return AddFields(ctx, stackdriver.Trace(metadata.Project(), sc.TraceID().String(), sc.SpanID().String(), sc.IsSampled())...)
+}
+
+// errorCode returns the twirp error code for err. Errors that are not twirp
+// errors are reported to clients as internal errors.
+func errorCode(err error) twirp.ErrorCode {
+	var twerr twirp.Error
+	if errors.As(err, &twerr) {
+		return twerr.Code()
+	}
+
+	return twirp.Internal
+}
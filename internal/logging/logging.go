@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"runtime"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -13,25 +16,113 @@ import (
 	"github.com/bakins/twirp-todo-example/internal/stackdriver"
 )
 
-type Config struct{}
+type Config struct {
+	Level string `kong:"default=info,enum='debug,info,warn,error'"`
+	// Development logs human readable lines to the console rather than
+	// Stackdriver formatted JSON.
+	Development bool `kong:""`
+	// Redact is a list of field keys whose values are masked.
+	Redact []string `kong:""`
+	// Labels are added to every log entry.
+	Labels map[string]string `kong:""`
+	// StacktraceLevel is the lowest level that includes a stack trace. none
+	// disables stack traces. Errors are still reported to Error Reporting
+	// without one, using the caller location.
+	StacktraceLevel string `kong:"default=error,enum='debug,info,warn,error,none'"`
+	// InsertID adds a unique insert id to each entry, so Cloud Logging does not
+	// drop entries it considers duplicates.
+	InsertID bool `kong:""`
+	// Report decides which entries are reported to Error Reporting. The
+	// default reports errors. See stackdriver.ReportErrors.
+	Report stackdriver.ReportFunc `kong:"-"`
+}
+
+// StacktraceNone disables stack traces.
+const StacktraceNone = "none"
+
+// Build creates a logger and sets it as the zap global logger. The returned
+// level may be used to change the level at runtime. It is also an
+// http.Handler.
+func (c Config) Build(ctx context.Context) (*zap.Logger, zap.AtomicLevel, error) {
+	level := zap.NewAtomicLevelAt(zap.InfoLevel)
+
+	if c.Level != "" {
+		if err := level.UnmarshalText([]byte(c.Level)); err != nil {
+			return nil, level, fmt.Errorf("failed to parse log level %q %w", c.Level, err)
+		}
+	}
+
+	var core zapcore.Core
+
+	if c.Development {
+		core = zapcore.NewCore(consoleEncoder(), Stdout, level)
+	} else {
+		core = zapcore.NewCore(stackdriver.Encoder(), Stdout, level)
+		sd := stackdriver.WrapCore(core, metadata.Service(), metadata.Version()).
+			WithLabels(metadataLabels()).
+			WithLabels(c.Labels)
 
-func (c Config) Build(ctx context.Context) *zap.Logger {
-	enc := stackdriver.Encoder()
+		if c.InsertID {
+			sd = sd.WithInsertID()
+		}
 
-	core := zapcore.NewCore(enc, Stdout, zap.NewAtomicLevelAt(zap.InfoLevel))
+		if c.Report != nil {
+			sd = sd.WithReport(c.Report)
+		}
 
-	wrapped := stackdriver.WrapCore(core, metadata.Service(), metadata.Version())
+		core = sd
+	}
+
+	if len(c.Redact) > 0 {
+		core = RedactCore(core, c.Redact...)
+	}
 
-	logger := zap.New(
-		wrapped,
+	options := []zap.Option{
 		zap.ErrorOutput(Stderr),
 		zap.AddCaller(),
-		zap.AddStacktrace(zapcore.ErrorLevel),
-	)
+	}
+
+	switch c.StacktraceLevel {
+	case StacktraceNone:
+	case "":
+		options = append(options, zap.AddStacktrace(zapcore.ErrorLevel))
+	default:
+		var stacktrace zapcore.Level
+		if err := stacktrace.UnmarshalText([]byte(c.StacktraceLevel)); err != nil {
+			return nil, level, fmt.Errorf("failed to parse stacktrace level %q %w", c.StacktraceLevel, err)
+		}
+
+		options = append(options, zap.AddStacktrace(stacktrace))
+	}
+
+	logger := zap.New(core, options...)
 
 	zap.ReplaceGlobals(logger)
 
-	return logger
+	return logger, level, nil
+}
+
+// metadataLabels identifies the deploy that produced a log entry.
+func metadataLabels() map[string]string {
+	labels := map[string]string{}
+
+	if revision := metadata.Revision(); revision != "" {
+		labels["revision"] = revision
+	}
+
+	if configuration := metadata.Configuration(); configuration != "" {
+		labels["configuration"] = configuration
+	}
+
+	return labels
+}
+
+func consoleEncoder() zapcore.Encoder {
+	cfg := zap.NewDevelopmentEncoderConfig()
+	cfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	cfg.EncodeTime = zapcore.TimeEncoderOfLayout("15:04:05.000")
+
+	return zapcore.NewConsoleEncoder(cfg)
 }
 
 // LoggingError wraps an error with a logger
@@ -56,20 +147,53 @@ func NewLoggingError(logger *zap.Logger, message string, err error) *LoggingErro
 	return &l
 }
 
+// ErrFatal is returned by Fatal.
+var ErrFatal = errors.New("fatal")
+
+// Fatal logs at zap's fatal level, which is EMERGENCY in Stackdriver, using the
+// logger in the context. Unlike zap's Fatal, it does not exit. Instead it
+// returns an error wrapping ErrFatal that should be returned to Exit.
+func Fatal(ctx context.Context, msg string, fields ...zap.Field) error {
+	logger := FromContext(ctx)
+
+	ent := zapcore.Entry{
+		Time:    time.Now(),
+		Level:   zapcore.FatalLevel,
+		Message: msg,
+		Caller:  zapcore.NewEntryCaller(runtime.Caller(1)),
+	}
+
+	// the core is used directly as zap.Logger always exits on fatal
+	if ce := logger.Core().Check(ent, nil); ce != nil {
+		ce.Write(fields...)
+	}
+
+	return fmt.Errorf("%s %w", msg, ErrFatal)
+}
+
 // Exit returns an exit code.
 // if err is nil, 0 is returned.
-// If err is set, the error is logged.
+// If err is set, the error is logged unless it was returned by Fatal. The
+// logger is synced so the final entries are not lost.
 func Exit(err error) int {
 	if err == nil {
 		return 0
 	}
+
+	logger := zap.L()
+	defer func() { _ = logger.Sync() }()
+
+	if errors.Is(err, ErrFatal) {
+		return 1
+	}
+
 	var le *LoggingError
 	if errors.As(err, &le) {
 		le.logger.Error(le.message, zap.Error(err))
+		_ = le.logger.Sync()
 		return 1
 	}
 
-	logger := zap.L()
 	logger.Error("exit", zap.Error(err))
 	return 1
 }
@@ -81,11 +205,43 @@ var (
 
 type ctxMarker struct{}
 
+type requestIDMarker struct{}
+
 var (
-	ctxMarkerKey = &ctxMarker{}
-	nullLogger   = zap.NewNop()
+	ctxMarkerKey       = &ctxMarker{}
+	requestIDMarkerKey = &requestIDMarker{}
+	nullLogger         = zap.NewNop()
 )
 
+// WithRequestID stores the request id in the context and adds it to the
+// context logger.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, requestIDMarkerKey, id)
+
+	return AddFields(ctx, zap.String("requestId", id))
+}
+
+// RequestIDFromContext returns the request id stored in the context, or an
+// empty string if there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDMarkerKey).(string)
+
+	return id
+}
+
+// WithOperation adds an operation to the context logger, so that its log lines
+// may be grouped in Cloud Logging. The service name is used as the producer.
+// The last entry should include stackdriver.OperationLast.
+func WithOperation(ctx context.Context, id string) context.Context {
+	return AddFields(ctx, stackdriver.Operation(id, metadata.Service()))
+}
+
+// AddLabel adds a label to the context logger. Labels are only used by the
+// Stackdriver encoder, and may be used for filtering in Cloud Logging.
+func AddLabel(ctx context.Context, key string, value string) context.Context {
+	return AddFields(ctx, stackdriver.Label(key, value))
+}
+
 // AddFields adds zap fields to the logger.
 func AddFields(ctx context.Context, fields ...zapcore.Field) context.Context {
 	l, ok := ctx.Value(ctxMarkerKey).(*zap.Logger)
@@ -114,26 +270,39 @@ func ToContext(ctx context.Context, l *zap.Logger) context.Context {
 	return context.WithValue(ctx, ctxMarkerKey, l)
 }
 
+// Middleware adds logger to the context of each request.
+func Middleware(logger *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(ToContext(r.Context(), logger)))
+		})
+	}
+}
+
+// helperSkip skips the helpers below so the caller, and so the Stackdriver
+// source location, is the code that called them.
+var helperSkip = zap.AddCallerSkip(1)
+
 // Debug is equivalent to calling Debug on the zap.Logger in the context.
 // It is a no-op if the context does not contain a zap.Logger.
 func Debug(ctx context.Context, msg string, fields ...zap.Field) {
-	FromContext(ctx).Debug(msg, fields...)
+	FromContext(ctx).WithOptions(helperSkip).Debug(msg, fields...)
 }
 
 // Info is equivalent to calling Info on the zap.Logger in the context.
 // It is a no-op if the context does not contain a zap.Logger.
 func Info(ctx context.Context, msg string, fields ...zap.Field) {
-	FromContext(ctx).Info(msg, fields...)
+	FromContext(ctx).WithOptions(helperSkip).Info(msg, fields...)
 }
 
 // Warn is equivalent to calling Warn on the zap.Logger in the context.
 // It is a no-op if the context does not contain a zap.Logger.
 func Warn(ctx context.Context, msg string, fields ...zap.Field) {
-	FromContext(ctx).Warn(msg, fields...)
+	FromContext(ctx).WithOptions(helperSkip).Warn(msg, fields...)
 }
 
 // Error is equivalent to calling Error on the zap.Logger in the context.
 // It is a no-op if the context does not contain a zap.Logger.
 func Error(ctx context.Context, msg string, fields ...zap.Field) {
-	FromContext(ctx).Error(msg, fields...)
+	FromContext(ctx).WithOptions(helperSkip).Error(msg, fields...)
 }
@@ -0,0 +1,99 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/bakins/twirp-todo-example/internal/logging"
+)
+
+func TestStacktraceLevel(t *testing.T) {
+	stdout := logging.Stdout
+	t.Cleanup(func() { logging.Stdout = stdout })
+
+	tests := map[string]struct {
+		warn  bool
+		error bool
+	}{
+		"":     {error: true},
+		"warn": {warn: true, error: true},
+		"none": {},
+	}
+
+	for level, expected := range tests {
+		t.Run(level, func(t *testing.T) {
+			var buf bytes.Buffer
+			logging.Stdout = zapcore.AddSync(&buf)
+
+			logger, _, err := logging.Config{StacktraceLevel: level}.Build(context.Background())
+			require.NoError(t, err)
+
+			logger.Warn("warn")
+			logger.Error("error")
+
+			dec := json.NewDecoder(&buf)
+
+			for _, stack := range []bool{expected.warn, expected.error} {
+				var entry map[string]interface{}
+				require.NoError(t, dec.Decode(&entry))
+
+				_, ok := entry["stacktrace"]
+				require.Equal(t, stack, ok, entry["message"])
+			}
+		})
+	}
+
+	// errors are reported without a stack trace
+	var buf bytes.Buffer
+	logging.Stdout = zapcore.AddSync(&buf)
+
+	logger, _, err := logging.Config{StacktraceLevel: logging.StacktraceNone}.Build(context.Background())
+	require.NoError(t, err)
+
+	logger.Error("error")
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.Contains(t, entry, "context")
+}
+
+func TestFatal(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	ctx := logging.ToContext(context.Background(), zap.New(core))
+
+	err := logging.Fatal(ctx, "failed to start", zap.String("reason", "testing"))
+	require.ErrorIs(t, err, logging.ErrFatal)
+	require.Equal(t, 1, logging.Exit(err))
+
+	entries := logs.All()
+	require.Len(t, entries, 1)
+	require.Equal(t, zapcore.FatalLevel, entries[0].Level)
+	require.Equal(t, "failed to start", entries[0].Message)
+	require.True(t, entries[0].Caller.Defined)
+}
+
+func TestHelperCaller(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	ctx := logging.ToContext(context.Background(), zap.New(core, zap.AddCaller()))
+
+	logging.Debug(ctx, "debug")
+	logging.Info(ctx, "info")
+	logging.Warn(ctx, "warn")
+	logging.Error(ctx, "error")
+
+	entries := logs.All()
+	require.Len(t, entries, 4)
+
+	for _, entry := range entries {
+		require.True(t, entry.Caller.Defined, entry.Message)
+		require.Equal(t, "logging_test.go", filepath.Base(entry.Caller.File), entry.Message)
+	}
+}
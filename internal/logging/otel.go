@@ -0,0 +1,17 @@
+package logging
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+)
+
+// OTelErrorHandler returns an OpenTelemetry error handler that logs errors,
+// such as failed exports and dropped spans, as warnings. Register it with
+// otel.SetErrorHandler.
+func OTelErrorHandler(logger *zap.Logger) otel.ErrorHandler {
+	logger = logger.With(zap.String("component", "opentelemetry"))
+
+	return otel.ErrorHandlerFunc(func(err error) {
+		logger.Warn("OpenTelemetry error", zap.Error(err))
+	})
+}
@@ -0,0 +1,25 @@
+package logging_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/bakins/twirp-todo-example/internal/logging"
+)
+
+func TestOTelErrorHandler(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+
+	logging.OTelErrorHandler(zap.New(core)).Handle(errors.New("export failed"))
+
+	entries := logs.All()
+	require.Len(t, entries, 1)
+	require.Equal(t, zapcore.WarnLevel, entries[0].Level)
+	require.Equal(t, "export failed", entries[0].ContextMap()["error"])
+	require.Equal(t, "opentelemetry", entries[0].ContextMap()["component"])
+}
@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Redacted replaces the value of redacted fields.
+const Redacted = "***"
+
+// RedactCore wraps core so that the values of fields with any of the given keys
+// are replaced with Redacted before they are encoded. Keys are matched case
+// insensitively.
+func RedactCore(core zapcore.Core, keys ...string) zapcore.Core {
+	c := redactCore{
+		core: core,
+		keys: make(map[string]bool, len(keys)),
+	}
+
+	for _, k := range keys {
+		c.keys[strings.ToLower(k)] = true
+	}
+
+	return &c
+}
+
+type redactCore struct {
+	core zapcore.Core
+	keys map[string]bool
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	newcore := redactCore{
+		core: c.core.With(c.redact(fields)),
+		keys: c.keys,
+	}
+
+	return &newcore
+}
+
+func (c *redactCore) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(e.Level) {
+		return ce.AddCore(e, c)
+	}
+
+	return ce
+}
+
+func (c *redactCore) Enabled(l zapcore.Level) bool {
+	return c.core.Enabled(l)
+}
+
+func (c *redactCore) Sync() error {
+	return c.core.Sync()
+}
+
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.core.Write(ent, c.redact(fields))
+}
+
+// redact returns fields with matching values replaced. fields is copied
+// rather than modified as the caller may reuse it.
+func (c *redactCore) redact(fields []zapcore.Field) []zapcore.Field {
+	var out []zapcore.Field
+
+	for i := range fields {
+		if !c.keys[strings.ToLower(fields[i].Key)] {
+			continue
+		}
+
+		if out == nil {
+			out = make([]zapcore.Field, len(fields))
+			copy(out, fields)
+		}
+
+		out[i] = zap.String(fields[i].Key, Redacted)
+	}
+
+	if out == nil {
+		return fields
+	}
+
+	return out
+}
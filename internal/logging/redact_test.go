@@ -0,0 +1,28 @@
+package logging_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/bakins/twirp-todo-example/internal/logging"
+)
+
+func TestRedactCore(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+
+	logger := zap.New(logging.RedactCore(core, "password", "token"))
+	logger = logger.With(zap.String("Token", "secret-token"))
+
+	logger.Info("login", zap.String("password", "hunter2"), zap.String("user", "alice"))
+
+	entries := logs.All()
+	require.Len(t, entries, 1)
+
+	fields := entries[0].ContextMap()
+	require.Equal(t, logging.Redacted, fields["password"])
+	require.Equal(t, logging.Redacted, fields["Token"])
+	require.Equal(t, "alice", fields["user"])
+}
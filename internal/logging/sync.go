@@ -0,0 +1,42 @@
+package logging
+
+import (
+	"errors"
+	"os"
+	"syscall"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// Sync flushes logger. Syncing stdout or stderr fails on some platforms, such
+// as when they are a pipe or terminal, and those errors are ignored. Other
+// errors are returned.
+func Sync(logger *zap.Logger) error {
+	var errs error
+
+	for _, err := range multierr.Errors(logger.Sync()) {
+		if benignSyncError(err) {
+			continue
+		}
+
+		errs = multierr.Append(errs, err)
+	}
+
+	return errs
+}
+
+func benignSyncError(err error) bool {
+	var pathErr *os.PathError
+	if !errors.As(err, &pathErr) {
+		return false
+	}
+
+	if pathErr.Path != os.Stdout.Name() && pathErr.Path != os.Stderr.Name() {
+		return false
+	}
+
+	return errors.Is(pathErr.Err, syscall.EINVAL) ||
+		errors.Is(pathErr.Err, syscall.ENOTTY) ||
+		errors.Is(pathErr.Err, syscall.EBADF)
+}
@@ -0,0 +1,64 @@
+package logging_test
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/bakins/twirp-todo-example/internal/logging"
+)
+
+type syncer struct {
+	err error
+}
+
+func (s syncer) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (s syncer) Sync() error {
+	return s.err
+}
+
+func TestSync(t *testing.T) {
+	tests := map[string]struct {
+		err      error
+		expected bool
+	}{
+		"ok": {},
+		"stdout": {
+			err: &os.PathError{Op: "sync", Path: os.Stdout.Name(), Err: syscall.EINVAL},
+		},
+		"file": {
+			err:      &os.PathError{Op: "sync", Path: "/var/log/todo.log", Err: syscall.EINVAL},
+			expected: true,
+		},
+		"other": {
+			err:      errors.New("disk full"),
+			expected: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			core := zapcore.NewCore(
+				zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+				syncer{err: tt.err},
+				zapcore.InfoLevel,
+			)
+
+			err := logging.Sync(zap.New(core))
+			if tt.expected {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// https://cloud.google.com/run/docs/container-contract#metadata-server
+
+const (
+	metadataHost    = "metadata.google.internal"
+	metadataTimeout = time.Millisecond * 500
+)
+
+var (
+	metadataOnce sync.Once
+	metadataErr  error
+
+	// metadataClient does not use a proxy, as the metadata server is only
+	// reachable directly, and is not shared with callers of
+	// http.DefaultClient.
+	metadataClient = &http.Client{
+		Timeout:   metadataTimeout,
+		Transport: &http.Transport{},
+	}
+)
+
+// FromMetadataServer fills in the project and region from the GCP metadata
+// server, unless they are already set. Each is queried independently, so one
+// failing does not prevent the other being set. The server is only queried
+// once per process and the result is cached. An error is returned when not
+// running on GCP, and may be ignored.
+func FromMetadataServer(ctx context.Context) error {
+	metadataOnce.Do(func() {
+		metadataErr = fetchMetadata(ctx)
+	})
+
+	return metadataErr
+}
+
+func fetchMetadata(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, metadataTimeout)
+	defer cancel()
+
+	host := os.Getenv("GCE_METADATA_HOST")
+	if host == "" {
+		host = metadataHost
+	}
+
+	var firstErr error
+
+	fill := func(field *string, suffix string, convert func(string) string) {
+		globalMetadata.lock.RLock()
+		set := *field != ""
+		globalMetadata.lock.RUnlock()
+
+		if set {
+			return
+		}
+
+		value, err := getMetadata(ctx, host, suffix)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+
+			return
+		}
+
+		globalMetadata.lock.Lock()
+		defer globalMetadata.lock.Unlock()
+
+		if convert != nil {
+			value = convert(value)
+		}
+
+		if *field == "" {
+			*field = value
+		}
+	}
+
+	fill(&globalMetadata.config.Project, "project/project-id", nil)
+
+	// region is in the form projects/PROJECT_NUMBER/regions/REGION
+	fill(&globalMetadata.config.Region, "instance/region", path.Base)
+
+	return firstErr
+}
+
+func getMetadata(ctx context.Context, host string, suffix string) (string, error) {
+	url := "http://" + host + "/computeMetadata/v1/" + suffix
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create metadata request %w", err)
+	}
+
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := metadataClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to query metadata server %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status from metadata server %q %d", suffix, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return "", fmt.Errorf("failed to read metadata response %w", err)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
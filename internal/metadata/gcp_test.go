@@ -0,0 +1,67 @@
+package metadata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchMetadata(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			http.Error(w, "missing header", http.StatusForbidden)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/computeMetadata/v1/project/project-id":
+			_, _ = w.Write([]byte("my-project\n"))
+		case "/computeMetadata/v1/instance/region":
+			_, _ = w.Write([]byte("projects/123/regions/us-central1"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer svr.Close()
+
+	t.Setenv("GCE_METADATA_HOST", strings.TrimPrefix(svr.URL, "http://"))
+
+	t.Cleanup(Reset)
+
+	Reset()
+	require.NoError(t, fetchMetadata(context.Background()))
+	require.Equal(t, "my-project", Project())
+	require.Equal(t, "us-central1", Region())
+
+	// configured values are kept
+	FromConfig(Config{Project: "configured", Region: "europe-west1"})
+	require.NoError(t, fetchMetadata(context.Background()))
+	require.Equal(t, "configured", Project())
+	require.Equal(t, "europe-west1", Region())
+}
+
+func TestFetchMetadataPartial(t *testing.T) {
+	// only the project is available
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/computeMetadata/v1/project/project-id" {
+			http.NotFound(w, r)
+			return
+		}
+
+		_, _ = w.Write([]byte("my-project"))
+	}))
+	defer svr.Close()
+
+	t.Setenv("GCE_METADATA_HOST", strings.TrimPrefix(svr.URL, "http://"))
+
+	t.Cleanup(Reset)
+
+	Reset()
+	require.Error(t, fetchMetadata(context.Background()))
+	require.Equal(t, "my-project", Project())
+	require.Equal(t, "", Region())
+}
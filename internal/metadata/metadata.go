@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"runtime"
 	"runtime/debug"
 	"sync"
 )
@@ -10,10 +11,19 @@ import (
 type Config struct {
 	Service string `kong:"env=K_SERVICE"`
 	Version string `kong:""`
+	// Revision and Configuration are set by Cloud Run.
+	Revision      string `kong:"env=K_REVISION"`
+	Configuration string `kong:"env=K_CONFIGURATION"`
+	// Project is the Google Cloud project id. It is used to link logs to
+	// traces.
+	Project string `kong:"env=GOOGLE_CLOUD_PROJECT"`
+	// Region is where the service is running. It is read from the GCP
+	// metadata server if not set.
+	Region string `kong:"env=GOOGLE_CLOUD_REGION"`
 }
 
 type metadata struct {
-	lock   sync.Mutex
+	lock   sync.RWMutex
 	config Config
 }
 
@@ -36,26 +46,104 @@ func FromConfig(config Config) {
 }
 
 func Service() string {
-	globalMetadata.lock.Lock()
-	defer globalMetadata.lock.Unlock()
+	globalMetadata.lock.RLock()
+	defer globalMetadata.lock.RUnlock()
 
 	return globalMetadata.config.Service
 }
 
+func Revision() string {
+	globalMetadata.lock.RLock()
+	defer globalMetadata.lock.RUnlock()
+
+	return globalMetadata.config.Revision
+}
+
+func Configuration() string {
+	globalMetadata.lock.RLock()
+	defer globalMetadata.lock.RUnlock()
+
+	return globalMetadata.config.Configuration
+}
+
+func Project() string {
+	globalMetadata.lock.RLock()
+	defer globalMetadata.lock.RUnlock()
+
+	return globalMetadata.config.Project
+}
+
+func Region() string {
+	globalMetadata.lock.RLock()
+	defer globalMetadata.lock.RUnlock()
+
+	return globalMetadata.config.Region
+}
+
+// Version returns the configured version, falling back to the module version
+// from the build info.
 func Version() string {
-	globalMetadata.lock.Lock()
-	defer globalMetadata.lock.Unlock()
+	globalMetadata.lock.RLock()
+	version := globalMetadata.config.Version
+	globalMetadata.lock.RUnlock()
+
+	if version != "" {
+		return version
+	}
+
+	return buildVersion()
+}
+
+var (
+	buildVersionOnce  sync.Once
+	buildVersionValue string
+)
+
+// buildVersion only reads the build info once, as it does not change.
+func buildVersion() string {
+	buildVersionOnce.Do(func() {
+		if info, ok := debug.ReadBuildInfo(); ok {
+			buildVersionValue = info.Main.Version
+		}
+	})
+
+	return buildVersionValue
+}
 
-	if globalMetadata.config.Version != "" {
-		return globalMetadata.config.Version
+// Build describes the running binary.
+type Build struct {
+	Service   string `json:"service"`
+	Version   string `json:"version"`
+	GoVersion string `json:"goVersion"`
+	Revision  string `json:"revision,omitempty"`
+	Time      string `json:"time,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+}
+
+// BuildInfo returns the service, version, and version control information
+// for the running binary.
+func BuildInfo() Build {
+	b := Build{
+		Service:   Service(),
+		Version:   Version(),
+		GoVersion: runtime.Version(),
 	}
 
 	info, ok := debug.ReadBuildInfo()
 	if !ok {
-		return ""
+		return b
 	}
 
-	globalMetadata.config.Version = info.Main.Version
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			b.Revision = s.Value
+		case "vcs.time":
+			b.Time = s.Value
+		case "vcs.modified":
+			b.Modified = s.Value == "true"
+		}
+	}
 
-	return globalMetadata.config.Version
+	return b
 }
@@ -0,0 +1,209 @@
+package otel
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/bakins/twirp-todo-example/internal/logging"
+)
+
+// Supported OTLP protocols.
+const (
+	ProtocolHTTP = "http"
+	ProtocolGRPC = "grpc"
+)
+
+// ConnectionConfig configures how exporters connect to an OTLP collector.
+type ConnectionConfig struct {
+	Protocol string `kong:"default=http,enum='http,grpc'"`
+	// Insecure disables TLS. It is ignored if any of the TLS files are set.
+	Insecure bool `kong:"default=true,negatable"`
+	// Headers are sent with every export request, usually for authentication.
+	Headers map[string]string `kong:""`
+	// CAFile is used to verify the collector's certificate. The system roots
+	// are used by default.
+	CAFile string `kong:""`
+	// CertFile and KeyFile are a client certificate for mutual TLS.
+	CertFile string `kong:""`
+	KeyFile  string `kong:""`
+}
+
+func (c ConnectionConfig) traceExporter(ctx context.Context, endpoint string) (*otlptrace.Exporter, error) {
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var client otlptrace.Client
+
+	switch c.Protocol {
+	case "", ProtocolHTTP:
+		options := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(endpoint),
+			otlptracehttp.WithHeaders(c.Headers),
+		}
+
+		if tlsConfig == nil {
+			options = append(options, otlptracehttp.WithInsecure())
+		} else {
+			options = append(options, otlptracehttp.WithTLSClientConfig(tlsConfig))
+		}
+
+		client = otlptracehttp.NewClient(options...)
+	case ProtocolGRPC:
+		options := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(endpoint),
+			otlptracegrpc.WithHeaders(c.Headers),
+		}
+
+		if tlsConfig == nil {
+			options = append(options, otlptracegrpc.WithInsecure())
+		} else {
+			options = append(options, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+		}
+
+		client = otlptracegrpc.NewClient(options...)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol %q", c.Protocol)
+	}
+
+	return otlptrace.New(ctx, client)
+}
+
+func (c ConnectionConfig) metricExporter(ctx context.Context, endpoint string) (*otlpmetric.Exporter, error) {
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var client otlpmetric.Client
+
+	switch c.Protocol {
+	case "", ProtocolHTTP:
+		options := []otlpmetrichttp.Option{
+			otlpmetrichttp.WithEndpoint(endpoint),
+			otlpmetrichttp.WithHeaders(c.Headers),
+		}
+
+		if tlsConfig == nil {
+			options = append(options, otlpmetrichttp.WithInsecure())
+		} else {
+			options = append(options, otlpmetrichttp.WithTLSClientConfig(tlsConfig))
+		}
+
+		client = otlpmetrichttp.NewClient(options...)
+	case ProtocolGRPC:
+		options := []otlpmetricgrpc.Option{
+			otlpmetricgrpc.WithEndpoint(endpoint),
+			otlpmetricgrpc.WithHeaders(c.Headers),
+		}
+
+		if tlsConfig == nil {
+			options = append(options, otlpmetricgrpc.WithInsecure())
+		} else {
+			options = append(options, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+		}
+
+		client = otlpmetricgrpc.NewClient(options...)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol %q", c.Protocol)
+	}
+
+	return otlpmetric.New(ctx, client)
+}
+
+// dialTimeout limits how long check waits for the collector.
+const dialTimeout = 5 * time.Second
+
+// check returns an error if the collector at endpoint does not accept
+// connections. Exporters connect lazily, so without it a collector that is
+// down is only noticed when exports fail.
+func (c ConnectionConfig) check(ctx context.Context, endpoint string) error {
+	ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+
+	var d net.Dialer
+
+	conn, err := d.DialContext(ctx, "tcp", endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to connect to OTLP collector %q %w", endpoint, err)
+	}
+
+	return conn.Close()
+}
+
+// checkRequired checks the collector at endpoint. When required, an error is
+// returned if it is unreachable. Otherwise the check runs in the background so
+// startup is not delayed, and a warning is logged if it fails. Exports are
+// retried as usual either way.
+func (c ConnectionConfig) checkRequired(ctx context.Context, endpoint string, required bool) error {
+	if required {
+		return c.check(ctx, endpoint)
+	}
+
+	go func() {
+		err := c.check(ctx, endpoint)
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+
+		logging.Warn(ctx, "OTLP collector is unreachable, telemetry may be lost", zap.Error(err))
+	}()
+
+	return nil
+}
+
+// tlsConfig returns nil if the connection is insecure.
+func (c ConnectionConfig) tlsConfig() (*tls.Config, error) {
+	if c.Insecure && c.CAFile == "" && c.CertFile == "" && c.KeyFile == "" {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if c.CAFile != "" {
+		data, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file %q %w", c.CAFile, err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in CA file %q", c.CAFile)
+		}
+
+		cfg.RootCAs = pool
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		if c.CertFile == "" || c.KeyFile == "" {
+			return nil, errors.New("both cert file and key file must be set")
+		}
+
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate %q and key %q %w", c.CertFile, c.KeyFile, err)
+		}
+
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,62 @@
+package otel
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/bakins/twirp-todo-example/internal/logging"
+)
+
+func TestCheckRequired(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer listener.Close()
+
+	reachable := listener.Addr().String()
+
+	// a closed listener leaves a local address that refuses connections
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	unreachable := closed.Addr().String()
+	require.NoError(t, closed.Close())
+
+	var c ConnectionConfig
+
+	t.Run("reachable", func(t *testing.T) {
+		core, logs := observer.New(zapcore.WarnLevel)
+		ctx := logging.ToContext(context.Background(), zap.New(core))
+
+		require.NoError(t, c.checkRequired(ctx, reachable, true))
+		require.NoError(t, c.checkRequired(ctx, reachable, false))
+
+		time.Sleep(time.Millisecond * 100)
+		require.Equal(t, 0, logs.Len())
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		core, logs := observer.New(zapcore.WarnLevel)
+		ctx := logging.ToContext(context.Background(), zap.New(core))
+
+		require.NoError(t, c.checkRequired(ctx, unreachable, false))
+
+		// the check runs in the background
+		require.Eventually(t, func() bool {
+			return logs.FilterMessage("OTLP collector is unreachable, telemetry may be lost").Len() == 1
+		}, time.Second, time.Millisecond*10)
+	})
+
+	t.Run("required", func(t *testing.T) {
+		err := c.checkRequired(context.Background(), unreachable, true)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), unreachable)
+	})
+}
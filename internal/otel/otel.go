@@ -2,17 +2,25 @@ package otel
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"sort"
 	"time"
 
 	gcppropagator "github.com/GoogleCloudPlatform/opentelemetry-operations-go/propagator"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/metric/global"
 	"go.opentelemetry.io/otel/propagation"
 	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
+	"go.opentelemetry.io/otel/sdk/metric/export"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
 	processor "go.opentelemetry.io/otel/sdk/metric/processor/basic"
 	"go.opentelemetry.io/otel/sdk/metric/selector/simple"
 	"go.opentelemetry.io/otel/sdk/resource"
@@ -21,38 +29,94 @@ import (
 	"github.com/bakins/twirp-todo-example/internal/metadata"
 )
 
+// Supported trace exporters.
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
 type TraceConfig struct {
-	Endpoint string `kong:""`
+	// Exporter is where spans are sent. stdout prints spans to stderr, which
+	// is useful for local development without a collector.
+	Exporter   string           `kong:"default=otlp,enum='otlp,stdout'"`
+	Endpoint   string           `kong:""`
+	Connection ConnectionConfig `kong:"embed"`
+	// SampleRatio is the fraction of traces to sample. When unset, every trace
+	// is sampled.
+	SampleRatio float64 `kong:""`
+	// IgnoreParent makes sampling decisions without regard to whether the
+	// parent span was sampled. Only used with SampleRatio.
+	IgnoreParent bool `kong:""`
+	// Required fails startup if the collector at Endpoint is unreachable.
+	// Otherwise a warning is logged and spans are dropped until it is
+	// reachable.
+	Required bool `kong:""`
 }
 
-func (c TraceConfig) Build(ctx context.Context) (func(), error) {
-	if c.Endpoint == "" {
-		return func() {}, nil
-	}
-
-	exp, err := otlptracehttp.New(
-		ctx,
-		otlptracehttp.WithEndpoint(c.Endpoint),
-		otlptracehttp.WithInsecure(),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create trace exporter %w", err)
-	}
+// ResourceConfig describes the resource shared by traces and metrics.
+type ResourceConfig struct {
+	// Attributes are added to the resource, after any from
+	// OTEL_RESOURCE_ATTRIBUTES.
+	Attributes map[string]string `kong:""`
+}
 
+// Build returns the resource to pass to TraceConfig.Build and
+// MetricsConfig.Build. Built in attributes take precedence over Attributes,
+// which take precedence over OTEL_RESOURCE_ATTRIBUTES.
+func (c ResourceConfig) Build(ctx context.Context) (*resource.Resource, error) {
 	r, err := resource.New(
 		ctx,
+		resource.WithFromEnv(),
+		resource.WithAttributes(configAttributes(c.Attributes)...),
 		resource.WithAttributes(
 			attribute.String("service", metadata.Service()),
 			attribute.String("version", metadata.Version()),
+			attribute.String("revision", metadata.Revision()),
+			attribute.String("configuration", metadata.Configuration()),
 		),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create resource %w", err)
 	}
 
+	return r, nil
+}
+
+// Build configures the global tracer provider, describing spans with r.
+func (c TraceConfig) Build(ctx context.Context, r *resource.Resource) (func(), error) {
+	var (
+		exp trace.SpanExporter
+		err error
+	)
+
+	switch c.Exporter {
+	case "", ExporterOTLP:
+		if c.Endpoint == "" {
+			return func() {}, nil
+		}
+
+		if err := c.Connection.checkRequired(ctx, c.Endpoint, c.Required); err != nil {
+			return nil, err
+		}
+
+		exp, err = c.Connection.traceExporter(ctx, c.Endpoint)
+	case ExporterStdout:
+		// stdout is left for logs
+		exp, err = stdouttrace.New(
+			stdouttrace.WithWriter(os.Stderr),
+			stdouttrace.WithPrettyPrint(),
+		)
+	default:
+		return nil, fmt.Errorf("unsupported trace exporter %q", c.Exporter)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter %w", err)
+	}
+
 	tp := trace.NewTracerProvider(
 		trace.WithBatcher(exp),
-		trace.WithSampler(trace.AlwaysSample()),
+		trace.WithSampler(c.sampler()),
 		trace.WithResource(r),
 	)
 
@@ -65,6 +129,8 @@ func (c TraceConfig) Build(ctx context.Context) (func(), error) {
 			propagation.Baggage{},
 		))
 
+	// shutting down the provider flushes the batcher, so spans ended just
+	// before exit are still exported.
 	cleanup := func() {
 		_ = tp.Shutdown(context.Background())
 		_ = exp.Shutdown(context.Background())
@@ -72,42 +138,161 @@ func (c TraceConfig) Build(ctx context.Context) (func(), error) {
 	return cleanup, nil
 }
 
+// configAttributes returns attributes sorted by key, so resources are
+// consistent between runs.
+func configAttributes(m map[string]string) []attribute.KeyValue {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	attrs := make([]attribute.KeyValue, 0, len(keys))
+	for _, k := range keys {
+		attrs = append(attrs, attribute.String(k, m[k]))
+	}
+
+	return attrs
+}
+
+func (c TraceConfig) sampler() trace.Sampler {
+	if c.SampleRatio <= 0 || c.SampleRatio >= 1 {
+		return trace.AlwaysSample()
+	}
+
+	sampler := trace.TraceIDRatioBased(c.SampleRatio)
+	if c.IgnoreParent {
+		return sampler
+	}
+
+	return trace.ParentBased(sampler)
+}
+
 type MetricsConfig struct {
-	Endpoint string `kong:""`
+	Endpoint   string           `kong:""`
+	Connection ConnectionConfig `kong:"embed"`
+	// Prometheus exposes metrics for scraping rather than pushing them to
+	// Endpoint.
+	Prometheus bool `kong:""`
+	// CollectPeriod is how often metrics are collected and pushed to
+	// Endpoint.
+	CollectPeriod time.Duration `kong:"default=10s"`
+	// Aggregation is histogram to record the distribution of histogram
+	// instruments, or inexpensive to record only their sum, which is cheaper
+	// to export. Gauges always record their last value.
+	Aggregation string `kong:"default=histogram,enum='histogram,inexpensive'"`
+	// Required fails startup if the collector at Endpoint is unreachable.
+	// Otherwise a warning is logged and metrics are dropped until it is
+	// reachable.
+	Required bool `kong:""`
 }
 
-func (c MetricsConfig) Build(ctx context.Context) (func(), error) {
+// Build configures the global meter provider, describing metrics with r. When
+// Prometheus is enabled, the returned handler serves the metrics, otherwise it
+// is nil.
+func (c MetricsConfig) Build(ctx context.Context, r *resource.Resource) (http.Handler, func(), error) {
+	if c.Prometheus {
+		if c.Endpoint != "" {
+			return nil, nil, errors.New("metrics endpoint and prometheus are mutually exclusive")
+		}
+
+		exp, err := c.buildPrometheus(r)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return exp, func() {}, nil
+	}
+
 	if c.Endpoint == "" {
-		return func() {}, nil
+		return nil, func() {}, nil
 	}
 
-	exp, err := otlpmetrichttp.New(
-		ctx,
-		otlpmetrichttp.WithEndpoint(c.Endpoint),
-		otlpmetrichttp.WithInsecure(),
-	)
+	cleanup, err := c.buildOTLP(ctx, r)
+
+	return nil, cleanup, err
+}
+
+// Supported metric aggregations.
+const (
+	AggregationHistogram   = "histogram"
+	AggregationInexpensive = "inexpensive"
+)
+
+func (c MetricsConfig) selector() (export.AggregatorSelector, error) {
+	switch c.Aggregation {
+	case "", AggregationHistogram:
+		return simple.NewWithHistogramDistribution(), nil
+	case AggregationInexpensive:
+		return simple.NewWithInexpensiveDistribution(), nil
+	default:
+		return nil, fmt.Errorf("unsupported metric aggregation %q", c.Aggregation)
+	}
+}
+
+func (c MetricsConfig) buildPrometheus(r *resource.Resource) (*otelprometheus.Exporter, error) {
+	registry := prometheus.NewRegistry()
+
+	if err := registry.Register(collectors.NewGoCollector()); err != nil {
+		return nil, fmt.Errorf("failed to register go collector %w", err)
+	}
+
+	if err := registry.Register(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{})); err != nil {
+		return nil, fmt.Errorf("failed to register process collector %w", err)
+	}
+
+	selector, err := c.selector()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create metrics exporter %w", err)
+		return nil, err
 	}
 
-	r, err := resource.New(
-		ctx,
-		resource.WithAttributes(
-			attribute.String("service", metadata.Service()),
-			attribute.String("version", metadata.Version()),
+	ctrl := controller.New(
+		processor.NewFactory(
+			selector,
+			aggregation.CumulativeTemporalitySelector(),
+			processor.WithMemory(true),
 		),
+		controller.WithResource(r),
 	)
+
+	exp, err := otelprometheus.New(otelprometheus.Config{Registry: registry}, ctrl)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create resource %w", err)
+		return nil, fmt.Errorf("failed to create prometheus exporter %w", err)
+	}
+
+	global.SetMeterProvider(exp.MeterProvider())
+
+	return exp, nil
+}
+
+func (c MetricsConfig) buildOTLP(ctx context.Context, r *resource.Resource) (func(), error) {
+	if err := c.Connection.checkRequired(ctx, c.Endpoint, c.Required); err != nil {
+		return nil, err
+	}
+
+	exp, err := c.Connection.metricExporter(ctx, c.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metrics exporter %w", err)
+	}
+
+	selector, err := c.selector()
+	if err != nil {
+		return nil, err
+	}
+
+	period := c.CollectPeriod
+	if period <= 0 {
+		period = 10 * time.Second
 	}
 
 	pusher := controller.New(
 		processor.NewFactory(
-			simple.NewWithHistogramDistribution(),
+			selector,
 			exp,
 		),
 		controller.WithExporter(exp),
-		controller.WithCollectPeriod(time.Second),
+		controller.WithCollectPeriod(period),
 		controller.WithResource(r),
 	)
 
@@ -0,0 +1,42 @@
+package otel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestSharedResource(t *testing.T) {
+	ctx := context.Background()
+
+	r, err := ResourceConfig{Attributes: map[string]string{"team": "todo"}}.Build(ctx)
+	require.NoError(t, err)
+
+	value, ok := r.Set().Value(attribute.Key("team"))
+	require.True(t, ok)
+	require.Equal(t, "todo", value.AsString())
+
+	cleanup, err := TraceConfig{Exporter: ExporterStdout}.Build(ctx, r)
+	require.NoError(t, err)
+
+	defer cleanup()
+
+	_, span := otel.Tracer("test").Start(ctx, "test")
+	defer span.End()
+
+	var readOnly sdktrace.ReadOnlySpan
+
+	readOnly, ok = span.(sdktrace.ReadOnlySpan)
+	require.True(t, ok)
+
+	exp, err := MetricsConfig{}.buildPrometheus(r)
+	require.NoError(t, err)
+
+	// both are described by the same resource
+	require.True(t, readOnly.Resource().Equal(exp.Controller().Resource()))
+	require.True(t, readOnly.Resource().Equal(r))
+}
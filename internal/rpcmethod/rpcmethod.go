@@ -0,0 +1,42 @@
+// Package rpcmethod names the Twirp method being called, so interceptors can
+// be configured by method.
+package rpcmethod
+
+import (
+	"context"
+	"strings"
+
+	"github.com/twitchtv/twirp"
+	"github.com/twitchtv/twirp/ctxsetters"
+)
+
+// Name returns the method called, named package.Service/Method.
+func Name(ctx context.Context) string {
+	pkg, _ := twirp.PackageName(ctx)
+	service, _ := twirp.ServiceName(ctx)
+	method, _ := twirp.MethodName(ctx)
+
+	if pkg != "" {
+		service = pkg + "." + service
+	}
+
+	return service + "/" + method
+}
+
+// WithName returns a context naming the method as Twirp does when it is
+// called, so interceptors can be tested. name is package.Service/Method.
+func WithName(ctx context.Context, name string) context.Context {
+	service, method := name, ""
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		service, method = name[:i], name[i+1:]
+	}
+
+	if i := strings.LastIndex(service, "."); i >= 0 {
+		ctx = ctxsetters.WithPackageName(ctx, service[:i])
+		service = service[i+1:]
+	}
+
+	ctx = ctxsetters.WithServiceName(ctx, service)
+
+	return ctxsetters.WithMethodName(ctx, method)
+}
@@ -0,0 +1,17 @@
+package rpcmethod_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/twirp-todo-example/internal/rpcmethod"
+)
+
+func TestName(t *testing.T) {
+	for _, name := range []string{"bakins.todo.v1.TodoService/GetTask", "TodoService/GetTask"} {
+		ctx := rpcmethod.WithName(context.Background(), name)
+		require.Equal(t, name, rpcmethod.Name(ctx))
+	}
+}
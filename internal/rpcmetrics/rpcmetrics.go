@@ -0,0 +1,117 @@
+// Package rpcmetrics records request counts and latency for Twirp methods.
+package rpcmetrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/twitchtv/twirp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/global"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/metric/unit"
+
+	"github.com/bakins/twirp-todo-example/internal/rpcmethod"
+)
+
+const (
+	methodKey = attribute.Key("method")
+	codeKey   = attribute.Key("code")
+)
+
+// codeOK is recorded for calls that do not return an error.
+const codeOK = "ok"
+
+type interceptorConfig struct {
+	provider metric.MeterProvider
+}
+
+type Option interface {
+	apply(*interceptorConfig)
+}
+
+type interceptorOptionFunc func(*interceptorConfig)
+
+func (f interceptorOptionFunc) apply(c *interceptorConfig) {
+	f(c)
+}
+
+// WithMeterProvider sets the provider used to create instruments. The default
+// is the global meter provider.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return interceptorOptionFunc(func(c *interceptorConfig) {
+		c.provider = provider
+	})
+}
+
+// Interceptor returns a twirp interceptor that records rpc.server.duration and
+// rpc.server.requests. Both have method, named package.Service/Method, and
+// code attributes. It complements the spans created by twirpotel.
+func Interceptor(options ...Option) (twirp.Interceptor, error) {
+	cfg := interceptorConfig{
+		provider: global.MeterProvider(),
+	}
+
+	for _, o := range options {
+		o.apply(&cfg)
+	}
+
+	meter := cfg.provider.Meter("github.com/bakins/twirp-todo-example/internal/rpcmetrics")
+
+	duration, err := meter.SyncFloat64().Histogram(
+		"rpc.server.duration",
+		instrument.WithDescription("duration of RPCs"),
+		instrument.WithUnit(unit.Milliseconds),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create duration histogram %w", err)
+	}
+
+	requests, err := meter.SyncInt64().Counter(
+		"rpc.server.requests",
+		instrument.WithDescription("number of RPCs"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create requests counter %w", err)
+	}
+
+	interceptor := func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			start := time.Now()
+
+			resp, err := next(ctx, req)
+
+			attrs := []attribute.KeyValue{
+				methodKey.String(rpcmethod.Name(ctx)),
+				codeKey.String(errorCode(err)),
+			}
+
+			elapsed := float64(time.Since(start)) / float64(time.Millisecond)
+
+			duration.Record(ctx, elapsed, attrs...)
+			requests.Add(ctx, 1, attrs...)
+
+			return resp, err
+		}
+	}
+
+	return interceptor, nil
+}
+
+// errorCode returns the twirp error code for err. Errors that are not twirp
+// errors are reported to clients as internal errors.
+func errorCode(err error) string {
+	if err == nil {
+		return codeOK
+	}
+
+	var twerr twirp.Error
+	if errors.As(err, &twerr) {
+		return string(twerr.Code())
+	}
+
+	return string(twirp.Internal)
+}
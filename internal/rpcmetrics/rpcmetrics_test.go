@@ -0,0 +1,62 @@
+package rpcmetrics_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metrictest"
+
+	"github.com/bakins/twirp-todo-example/internal/rpcmethod"
+	"github.com/bakins/twirp-todo-example/internal/rpcmetrics"
+)
+
+func TestInterceptor(t *testing.T) {
+	provider, exporter := metrictest.NewTestMeterProvider()
+
+	interceptor, err := rpcmetrics.Interceptor(rpcmetrics.WithMeterProvider(provider))
+	require.NoError(t, err)
+
+	method := interceptor(func(ctx context.Context, req interface{}) (interface{}, error) {
+		if req == nil {
+			return nil, twirp.NotFoundError("missing")
+		}
+
+		return req, nil
+	})
+
+	ctx := rpcmethod.WithName(context.Background(), "bakins.todo.v1.TodoService/GetTask")
+
+	for i := 0; i < 2; i++ {
+		resp, err := method(ctx, "ok")
+		require.NoError(t, err)
+		require.Equal(t, "ok", resp)
+	}
+
+	_, err = method(ctx, nil)
+	require.Error(t, err)
+
+	require.NoError(t, exporter.Collect(ctx))
+
+	tests := map[string]int64{
+		"ok":                   2,
+		string(twirp.NotFound): 1,
+	}
+
+	for code, expected := range tests {
+		attrs := []attribute.KeyValue{
+			attribute.String("method", "bakins.todo.v1.TodoService/GetTask"),
+			attribute.String("code", code),
+		}
+
+		requests, err := exporter.GetByNameAndAttributes("rpc.server.requests", attrs)
+		require.NoError(t, err, code)
+		require.Equal(t, expected, requests.Sum.AsInt64(), code)
+
+		duration, err := exporter.GetByNameAndAttributes("rpc.server.duration", attrs)
+		require.NoError(t, err, code)
+		require.Equal(t, uint64(expected), duration.Count, code)
+	}
+}
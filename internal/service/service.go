@@ -0,0 +1,109 @@
+// Package service builds the Twirp server for the todo service with its
+// interceptors, so the application and tests use the same stack.
+package service
+
+import (
+	"time"
+
+	"github.com/bakins/twirpotel"
+	"github.com/twitchtv/twirp"
+
+	"github.com/bakins/twirp-todo-example/internal/auth"
+	"github.com/bakins/twirp-todo-example/internal/logging"
+	"github.com/bakins/twirp-todo-example/internal/rpcmetrics"
+	"github.com/bakins/twirp-todo-example/internal/timeout"
+	"github.com/bakins/twirp-todo-example/internal/todo"
+	"github.com/bakins/twirp-todo-example/internal/validate"
+	pb "github.com/bakins/twirp-todo-example/proto"
+)
+
+// DefaultTimeout is used when WithTimeout is not set. It matches the default
+// of timeout.Config.
+const DefaultTimeout = time.Second * 30
+
+type config struct {
+	timeout     twirp.Interceptor
+	verifier    auth.Verifier
+	authOptions []auth.Option
+	limits      twirp.Interceptor
+}
+
+type Option interface {
+	apply(*config)
+}
+
+type serviceOptionFunc func(*config)
+
+func (f serviceOptionFunc) apply(c *config) {
+	f(c)
+}
+
+// WithTimeout bounds each call with interceptor, usually built with
+// timeout.Config. By default calls time out after DefaultTimeout. A nil
+// interceptor disables the timeout.
+func WithTimeout(interceptor twirp.Interceptor) Option {
+	return serviceOptionFunc(func(c *config) {
+		c.timeout = interceptor
+	})
+}
+
+// WithAuth requires callers to be authenticated by verifier. Requests must
+// pass through auth.Middleware.
+func WithAuth(verifier auth.Verifier, options ...auth.Option) Option {
+	return serviceOptionFunc(func(c *config) {
+		c.verifier = verifier
+		c.authOptions = options
+	})
+}
+
+// WithLimits limits concurrent calls with interceptor, usually built with
+// concurrency.Config. It runs after authentication. A nil interceptor is
+// ignored.
+func WithLimits(interceptor twirp.Interceptor) Option {
+	return serviceOptionFunc(func(c *config) {
+		c.limits = interceptor
+	})
+}
+
+// New returns the Twirp server for s. Calls are traced, counted, shed while
+// the database is slow, bounded by a timeout, authenticated if WithAuth is
+// set, limited, logged, and validated, in that order.
+func New(s *todo.Server, options ...Option) (pb.TwirpServer, error) {
+	cfg := config{
+		timeout: timeout.Interceptor(DefaultTimeout, nil),
+	}
+
+	for _, o := range options {
+		o.apply(&cfg)
+	}
+
+	metrics, err := rpcmetrics.Interceptor()
+	if err != nil {
+		return nil, err
+	}
+
+	// metrics wraps the other interceptors so it records the code returned
+	// to clients.
+	interceptors := []twirp.Interceptor{
+		twirpotel.ServerInterceptor(),
+		metrics,
+		s.LoadShedding(),
+	}
+
+	if cfg.timeout != nil {
+		interceptors = append(interceptors, cfg.timeout)
+	}
+
+	if cfg.verifier != nil {
+		interceptors = append(interceptors, auth.Interceptor(cfg.verifier, cfg.authOptions...))
+	}
+
+	// limits run after auth so unauthenticated calls do not use up slots
+	if cfg.limits != nil {
+		interceptors = append(interceptors, cfg.limits)
+	}
+
+	interceptors = append(interceptors, logging.Interceptor(), validate.Interceptor(validate.WithFunc(todo.Validate)))
+
+	return pb.NewTodoServiceServer(s, twirp.WithServerInterceptors(interceptors...)), nil
+}
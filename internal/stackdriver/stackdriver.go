@@ -1,8 +1,16 @@
 package stackdriver
 
 import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"net/http"
 	"runtime"
+	"sort"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
@@ -63,6 +71,47 @@ type HTTPRequest struct {
 	Referer       string `json:"referer"`
 	Protocol      string `json:"protocol"`
 	Status        int    `json:"status"`
+
+	CacheLookup                    bool   `json:"cacheLookup"`
+	CacheHit                       bool   `json:"cacheHit"`
+	CacheValidatedWithOriginServer bool   `json:"cacheValidatedWithOriginServer"`
+	CacheFillBytes                 string `json:"cacheFillBytes"`
+}
+
+// NewHTTPRequest creates an HTTPRequest describing r and its response.
+func NewHTTPRequest(r *http.Request, status int, responseSize int64, latency time.Duration) *HTTPRequest {
+	req := HTTPRequest{
+		RequestMethod: r.Method,
+		RequestURL:    r.URL.String(),
+		Latency:       FormatDuration(latency),
+		ResponseSize:  strconv.FormatInt(responseSize, 10),
+		UserAgent:     r.UserAgent(),
+		RemoteIP:      remoteIP(r),
+		Referer:       r.Referer(),
+		Protocol:      r.Proto,
+		Status:        status,
+	}
+
+	if r.ContentLength > 0 {
+		req.RequestSize = strconv.FormatInt(r.ContentLength, 10)
+	}
+
+	return &req
+}
+
+// FormatDuration formats d as seconds with an "s" suffix, such as "0.012s",
+// as expected by the latency field. Latency in any other format is dropped.
+func FormatDuration(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
 }
 
 func (req *HTTPRequest) MarshalLogObject(enc zapcore.ObjectEncoder) error {
@@ -73,7 +122,7 @@ func (req *HTTPRequest) MarshalLogObject(enc zapcore.ObjectEncoder) error {
 	enc.AddString("requestUrl", req.RequestURL)
 	enc.AddString("requestSize", req.RequestSize)
 
-	if req.Status == 0 {
+	if req.Status != 0 {
 		enc.AddInt("status", req.Status)
 	}
 	enc.AddString("responseSize", req.ResponseSize)
@@ -102,12 +151,22 @@ func (req *HTTPRequest) MarshalLogObject(enc zapcore.ObjectEncoder) error {
 		enc.AddString("latency", req.Latency)
 	}
 
-	/*
+	if req.CacheLookup {
 		enc.AddBool("cacheLookup", req.CacheLookup)
+	}
+
+	if req.CacheHit {
 		enc.AddBool("cacheHit", req.CacheHit)
+	}
+
+	if req.CacheValidatedWithOriginServer {
 		enc.AddBool("cacheValidatedWithOriginServer", req.CacheValidatedWithOriginServer)
+	}
+
+	if req.CacheFillBytes != "" {
 		enc.AddString("cacheFillBytes", req.CacheFillBytes)
-	*/
+	}
+
 	return nil
 }
 
@@ -202,9 +261,91 @@ func newServiceContext(name, version string) *serviceContext {
 	}
 }
 
+const labelsKey = "logging.googleapis.com/labels"
+
+// Label adds a label to the log entry. Labels are collected by Core and
+// written as a single labels object, so they can be added at any time, such as
+// to a request scoped logger.
+//
+// see: https://cloud.google.com/logging/docs/structured-logging#special-payload-fields
+func Label(key string, value string) zap.Field {
+	return zap.String(labelsKey+"/"+key, value)
+}
+
+// labels are encoded with sorted keys so that log lines are stable.
+type labels map[string]string
+
+func (l labels) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	keys := make([]string, 0, len(l))
+	for k := range l {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		enc.AddString(k, l[k])
+	}
+
+	return nil
+}
+
+// merge returns a copy of l with any label fields added. The remaining fields
+// are also returned.
+func (l labels) merge(fields []zapcore.Field) (labels, []zapcore.Field) {
+	var (
+		merged labels
+		rest   []zapcore.Field
+	)
+
+	for i := range fields {
+		key := strings.TrimPrefix(fields[i].Key, labelsKey+"/")
+		if key == fields[i].Key || fields[i].Type != zapcore.StringType {
+			if merged != nil {
+				rest = append(rest, fields[i])
+			}
+			continue
+		}
+
+		if merged == nil {
+			merged = make(labels, len(l)+1)
+			for k, v := range l {
+				merged[k] = v
+			}
+
+			rest = append(make([]zapcore.Field, 0, len(fields)), fields[:i]...)
+		}
+
+		merged[key] = fields[i].String
+	}
+
+	if merged == nil {
+		return l, fields
+	}
+
+	return merged, rest
+}
+
 type Core struct {
-	core    zapcore.Core
-	service *serviceContext
+	core      zapcore.Core
+	service   *serviceContext
+	labels    labels
+	insertID  *insertIDs
+	operation *operation
+	report    ReportFunc
+	// with holds the fields added by With, which the wrapped core has
+	// already encoded, so they can be passed to report.
+	with []zapcore.Field
+}
+
+// ReportFunc decides whether an entry is reported to Error Reporting. The
+// fields include those added to the logger with With, followed by those
+// passed with the entry.
+type ReportFunc func(zapcore.Entry, []zapcore.Field) bool
+
+// ReportErrors reports entries at error level and above. It is the default.
+func ReportErrors(ent zapcore.Entry, _ []zapcore.Field) bool {
+	return zapcore.ErrorLevel.Enabled(ent.Level)
 }
 
 func WrapCore(core zapcore.Core, serviceName string, serviceVersion string) *Core {
@@ -219,18 +360,62 @@ func WrapCore(core zapcore.Core, serviceName string, serviceVersion string) *Cor
 	c := Core{
 		core:    core,
 		service: newServiceContext(serviceName, serviceVersion),
+		report:  ReportErrors,
 	}
 
 	return &c
 }
 
+// WithLabels returns a copy of c that adds labels to every log entry.
+func (c *Core) WithLabels(l map[string]string) *Core {
+	merged := make(labels, len(c.labels)+len(l))
+	for k, v := range c.labels {
+		merged[k] = v
+	}
+
+	for k, v := range l {
+		merged[k] = v
+	}
+
+	newcore := *c
+	newcore.labels = merged
+
+	return &newcore
+}
+
+// WithInsertID returns a copy of c that adds a unique insert id to every log
+// entry. Cloud Logging may drop entries with the same timestamp and payload as
+// duplicates, which an insert id prevents.
+func (c *Core) WithInsertID() *Core {
+	newcore := *c
+	newcore.insertID = newInsertIDs()
+	return &newcore
+}
+
+// WithReport returns a copy of c that uses report to decide which entries are
+// reported to Error Reporting, such as to keep expected errors out of it. A nil
+// report restores the default, ReportErrors.
+func (c *Core) WithReport(report ReportFunc) *Core {
+	if report == nil {
+		report = ReportErrors
+	}
+
+	newcore := *c
+	newcore.report = report
+	return &newcore
+}
+
 func (c *Core) With(fields []zap.Field) zapcore.Core {
+	labels, fields := c.labels.merge(fields)
+	op, _, fields := c.operation.merge(fields)
+
 	core := c.core.With(fields)
 
-	newcore := Core{
-		core:    core,
-		service: c.service,
-	}
+	newcore := *c
+	newcore.core = core
+	newcore.labels = labels
+	newcore.operation = op
+	newcore.with = append(c.with[:len(c.with):len(c.with)], fields...)
 
 	return &newcore
 }
@@ -252,10 +437,29 @@ func (c *Core) Sync() error {
 }
 
 func (c *Core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	labels, fields := c.labels.merge(fields)
+	if len(labels) > 0 {
+		fields = append(fields, zap.Object(labelsKey, labels))
+	}
+
+	op, last, fields := c.operation.merge(fields)
+	if op != nil {
+		fields = append(fields, zap.Object(operationKey, op.entry(last)))
+	}
+
 	fields = c.withServiceContext(fields)
 
-	if zapcore.ErrorLevel.Enabled(ent.Level) {
+	if c.insertID != nil {
+		fields = append(fields, zap.String(insertIDKey, c.insertID.next()))
+	}
+
+	report := c.report(ent, append(c.with[:len(c.with):len(c.with)], fields...))
+
+	if report || zapcore.ErrorLevel.Enabled(ent.Level) {
 		fields = c.withSourceLocation(ent, fields)
+	}
+
+	if report {
 		fields = c.withErrorReport(ent, fields)
 	}
 
@@ -303,6 +507,133 @@ func (c *Core) withSourceLocation(ent zapcore.Entry, fields []zapcore.Field) []z
 	return append(fields, SourceLocation(ent.Caller.PC, ent.Caller.File, ent.Caller.Line, true))
 }
 
+const (
+	traceKey        = "logging.googleapis.com/trace"
+	spanIDKey       = "logging.googleapis.com/spanId"
+	traceSampledKey = "logging.googleapis.com/trace_sampled"
+)
+
+// Trace adds the fields used to link a log line to a trace. The trace is
+// formatted as projects/PROJECT/traces/TRACE_ID, which is required for Cloud
+// Console to find the trace. If projectID is empty, the bare trace id is used.
+// sampled reports whether the trace was sampled, and so may be exported.
+//
+// see: https://cloud.google.com/logging/docs/structured-logging#special-payload-fields
+func Trace(projectID string, traceID string, spanID string, sampled bool) []zap.Field {
+	trace := traceID
+	if projectID != "" {
+		trace = "projects/" + projectID + "/traces/" + traceID
+	}
+
+	return []zap.Field{
+		zap.String(traceKey, trace),
+		zap.String(spanIDKey, spanID),
+		zap.Bool(traceSampledKey, sampled),
+	}
+}
+
+const (
+	operationKey     = "logging.googleapis.com/operation"
+	operationLastKey = operationKey + "/last"
+)
+
+// Operation groups log entries, such as those for a single request, so they
+// can be viewed together. Core marks the first entry written with the
+// operation, and OperationLast marks the final one.
+//
+// see: https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry#LogEntryOperation
+func Operation(id string, producer string) zap.Field {
+	return zap.Object(operationKey, &operation{id: id, producer: producer})
+}
+
+// OperationLast marks the entry as the last for its operation.
+func OperationLast() zap.Field {
+	return zap.Bool(operationLastKey, true)
+}
+
+// operation is shared by all entries for the operation, so only one of them
+// is marked first.
+type operation struct {
+	id       string
+	producer string
+	written  uint32
+}
+
+func (op *operation) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("id", op.id)
+	enc.AddString("producer", op.producer)
+	return nil
+}
+
+// entry returns the operation for the next entry written.
+func (op *operation) entry(last bool) operationEntry {
+	return operationEntry{
+		operation: op,
+		first:     atomic.CompareAndSwapUint32(&op.written, 0, 1),
+		last:      last,
+	}
+}
+
+func isOperationField(f zapcore.Field) bool {
+	if f.Key == operationLastKey {
+		return true
+	}
+
+	_, ok := f.Interface.(*operation)
+	return ok && f.Key == operationKey
+}
+
+// merge returns the operation set in fields, or op if there is none, and
+// whether the entry is marked last. The remaining fields are also returned.
+func (op *operation) merge(fields []zapcore.Field) (*operation, bool, []zapcore.Field) {
+	i := 0
+	for i < len(fields) && !isOperationField(fields[i]) {
+		i++
+	}
+
+	if i == len(fields) {
+		return op, false, fields
+	}
+
+	var last bool
+
+	rest := append(make([]zapcore.Field, 0, len(fields)), fields[:i]...)
+
+	for _, f := range fields[i:] {
+		switch {
+		case !isOperationField(f):
+			rest = append(rest, f)
+		case f.Key == operationLastKey:
+			last = f.Integer == 1
+		default:
+			op = f.Interface.(*operation)
+		}
+	}
+
+	return op, last, rest
+}
+
+type operationEntry struct {
+	*operation
+	first bool
+	last  bool
+}
+
+func (op operationEntry) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("id", op.id)
+	enc.AddString("producer", op.producer)
+
+	if op.first {
+		enc.AddBool("first", true)
+	}
+
+	if op.last {
+		enc.AddBool("last", true)
+	}
+
+	return nil
+}
+
 const sourceKey = "logging.googleapis.com/sourceLocation"
 
 // SourceLocation adds the correct Stackdriver "SourceLocation" field.
@@ -359,3 +690,30 @@ func newSource(pc uintptr, file string, line int, ok bool) *source {
 
 	return source
 }
+
+const insertIDKey = "logging.googleapis.com/insertId"
+
+// insertIDs generates ids that are unique across processes by combining a
+// random nonce with a counter.
+//
+// see: https://cloud.google.com/logging/docs/structured-logging#special-payload-fields
+type insertIDs struct {
+	nonce   string
+	counter uint64
+}
+
+func newInsertIDs() *insertIDs {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		// fall back to the time, which is unique enough across restarts
+		binary.BigEndian.PutUint64(b, uint64(time.Now().UnixNano()))
+	}
+
+	return &insertIDs{
+		nonce: hex.EncodeToString(b),
+	}
+}
+
+func (i *insertIDs) next() string {
+	return i.nonce + "-" + strconv.FormatUint(atomic.AddUint64(&i.counter, 1), 10)
+}
@@ -0,0 +1,213 @@
+package stackdriver_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/bakins/twirp-todo-example/internal/stackdriver"
+)
+
+func TestHTTPRequestStatus(t *testing.T) {
+	enc := zapcore.NewMapObjectEncoder()
+
+	req := stackdriver.HTTPRequest{
+		RequestMethod: "POST",
+		Status:        200,
+	}
+
+	require.NoError(t, req.MarshalLogObject(enc))
+	require.Equal(t, 200, enc.Fields["status"])
+
+	// status is omitted when unknown
+	enc = zapcore.NewMapObjectEncoder()
+
+	require.NoError(t, (&stackdriver.HTTPRequest{}).MarshalLogObject(enc))
+	require.NotContains(t, enc.Fields, "status")
+}
+
+func TestFormatDuration(t *testing.T) {
+	require.Equal(t, "0.012s", stackdriver.FormatDuration(time.Millisecond*12))
+	require.Equal(t, "3.5s", stackdriver.FormatDuration(time.Millisecond*3500))
+	require.Equal(t, "0s", stackdriver.FormatDuration(0))
+}
+
+func TestNewHTTPRequest(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/twirp/bakins.todo.v1.TodoService/ListTasks", strings.NewReader("{}"))
+	r.RemoteAddr = "10.0.0.1:1234"
+
+	req := stackdriver.NewHTTPRequest(r, http.StatusOK, 42, time.Second)
+
+	require.Equal(t, http.MethodPost, req.RequestMethod)
+	require.Equal(t, "2", req.RequestSize)
+	require.Equal(t, "42", req.ResponseSize)
+	require.Equal(t, "1s", req.Latency)
+	require.Equal(t, "10.0.0.1", req.RemoteIP)
+	require.Equal(t, http.StatusOK, req.Status)
+}
+
+func TestLabels(t *testing.T) {
+	var buf bytes.Buffer
+
+	core := zapcore.NewCore(stackdriver.Encoder(), zapcore.AddSync(&buf), zapcore.DebugLevel)
+	wrapped := stackdriver.WrapCore(core, "test", "v1").WithLabels(map[string]string{"region": "us-central1"})
+
+	logger := zap.New(wrapped).With(stackdriver.Label("tenant", "acme"))
+	logger.Info("testing", stackdriver.Label("request", "1"), zap.String("other", "value"))
+
+	var entry struct {
+		Labels map[string]string `json:"logging.googleapis.com/labels"`
+		Other  string            `json:"other"`
+	}
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.Equal(t, map[string]string{
+		"region":  "us-central1",
+		"tenant":  "acme",
+		"request": "1",
+	}, entry.Labels)
+	require.Equal(t, "value", entry.Other)
+}
+
+func TestInsertID(t *testing.T) {
+	var buf bytes.Buffer
+
+	core := zapcore.NewCore(stackdriver.Encoder(), zapcore.AddSync(&buf), zapcore.DebugLevel)
+
+	logger := zap.New(stackdriver.WrapCore(core, "test", "v1").WithInsertID())
+	logger.Info("first")
+	logger.With(zap.String("key", "value")).Info("second")
+
+	dec := json.NewDecoder(&buf)
+	seen := map[string]bool{}
+
+	for i := 0; i < 2; i++ {
+		var entry map[string]interface{}
+		require.NoError(t, dec.Decode(&entry))
+
+		id, ok := entry["logging.googleapis.com/insertId"].(string)
+		require.True(t, ok)
+		require.NotEmpty(t, id)
+		require.False(t, seen[id])
+		seen[id] = true
+	}
+
+	// insert ids are optional
+	buf.Reset()
+	zap.New(stackdriver.WrapCore(core, "test", "v1")).Info("testing")
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.NotContains(t, entry, "logging.googleapis.com/insertId")
+}
+
+func TestOperation(t *testing.T) {
+	var buf bytes.Buffer
+
+	core := zapcore.NewCore(stackdriver.Encoder(), zapcore.AddSync(&buf), zapcore.DebugLevel)
+
+	logger := zap.New(stackdriver.WrapCore(core, "test", "v1")).With(stackdriver.Operation("request-1", "todo"))
+	logger.Info("first")
+	logger.Info("middle")
+	logger.Info("last", stackdriver.OperationLast())
+
+	type operation struct {
+		ID       string `json:"id"`
+		Producer string `json:"producer"`
+		First    bool   `json:"first"`
+		Last     bool   `json:"last"`
+	}
+
+	expected := []operation{
+		{ID: "request-1", Producer: "todo", First: true},
+		{ID: "request-1", Producer: "todo"},
+		{ID: "request-1", Producer: "todo", Last: true},
+	}
+
+	dec := json.NewDecoder(&buf)
+
+	for _, op := range expected {
+		var entry map[string]json.RawMessage
+		require.NoError(t, dec.Decode(&entry))
+		require.NotContains(t, entry, "logging.googleapis.com/operation/last")
+
+		var actual operation
+		require.NoError(t, json.Unmarshal(entry["logging.googleapis.com/operation"], &actual))
+		require.Equal(t, op, actual)
+	}
+}
+
+func TestReport(t *testing.T) {
+	var buf bytes.Buffer
+
+	core := zapcore.NewCore(stackdriver.Encoder(), zapcore.AddSync(&buf), zapcore.DebugLevel)
+
+	expected := errors.New("expected")
+
+	// report warnings, except for an expected error
+	report := func(ent zapcore.Entry, fields []zapcore.Field) bool {
+		for _, f := range fields {
+			if err, ok := f.Interface.(error); ok && errors.Is(err, expected) {
+				return false
+			}
+		}
+
+		return zapcore.WarnLevel.Enabled(ent.Level)
+	}
+
+	logger := zap.New(stackdriver.WrapCore(core, "test", "v1").WithReport(report), zap.AddCaller())
+
+	tests := []struct {
+		log      func()
+		reported bool
+	}{
+		{log: func() { logger.Info("info") }},
+		{log: func() { logger.Warn("warn") }, reported: true},
+		{log: func() { logger.Error("error") }, reported: true},
+		{log: func() { logger.Error("error", zap.Error(expected)) }},
+		{log: func() { logger.With(zap.Error(expected)).Error("error") }},
+		{log: func() { logger.With(zap.String("key", "value")).Error("error") }, reported: true},
+	}
+
+	for _, test := range tests {
+		buf.Reset()
+		test.log()
+
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+
+		_, ok := entry["context"]
+		require.Equal(t, test.reported, ok, entry["message"])
+	}
+
+	// errors are reported by default
+	buf.Reset()
+	zap.New(stackdriver.WrapCore(core, "test", "v1"), zap.AddCaller()).Error("error")
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.Contains(t, entry, "context")
+}
+
+func TestTrace(t *testing.T) {
+	enc := zapcore.NewMapObjectEncoder()
+
+	for _, f := range stackdriver.Trace("project", "abc", "def", true) {
+		f.AddTo(enc)
+	}
+
+	require.Equal(t, map[string]interface{}{
+		"logging.googleapis.com/trace":         "projects/project/traces/abc",
+		"logging.googleapis.com/spanId":        "def",
+		"logging.googleapis.com/trace_sampled": true,
+	}, enc.Fields)
+}
@@ -0,0 +1,53 @@
+// Package timeout bounds how long Twirp methods may run.
+package timeout
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/twitchtv/twirp"
+
+	"github.com/bakins/twirp-todo-example/internal/rpcmethod"
+)
+
+type Config struct {
+	// Default applies to every method without an override. Zero means no
+	// timeout.
+	Default time.Duration `kong:"default=30s"`
+	// Methods overrides the timeout for methods named package.Service/Method.
+	Methods map[string]time.Duration `kong:""`
+}
+
+func (c Config) Build(ctx context.Context) twirp.Interceptor {
+	return Interceptor(c.Default, c.Methods)
+}
+
+// Interceptor returns a twirp interceptor that applies a timeout to the
+// context of every call. Methods named package.Service/Method in overrides use
+// that timeout instead. A zero timeout means no timeout. If the timeout
+// expires, a deadline_exceeded error is returned.
+func Interceptor(timeout time.Duration, overrides map[string]time.Duration) twirp.Interceptor {
+	return func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			d := timeout
+			if o, ok := overrides[rpcmethod.Name(ctx)]; ok {
+				d = o
+			}
+
+			if d <= 0 {
+				return next(ctx, req)
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+
+			resp, err := next(ctx, req)
+			if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, twirp.NewError(twirp.DeadlineExceeded, "request exceeded its deadline")
+			}
+
+			return resp, err
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package timeout_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+
+	"github.com/bakins/twirp-todo-example/internal/rpcmethod"
+	"github.com/bakins/twirp-todo-example/internal/timeout"
+)
+
+func TestInterceptor(t *testing.T) {
+	interceptor := timeout.Interceptor(time.Millisecond*10, map[string]time.Duration{
+		"bakins.todo.v1.TodoService/ListTasks": time.Second * 10,
+	})
+
+	method := interceptor(func(ctx context.Context, req interface{}) (interface{}, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Millisecond * 100):
+			return "ok", nil
+		}
+	})
+
+	ctx := context.Background()
+
+	_, err := method(rpcmethod.WithName(ctx, "bakins.todo.v1.TodoService/CreateTask"), nil)
+
+	var twerr twirp.Error
+	require.True(t, errors.As(err, &twerr))
+	require.Equal(t, twirp.DeadlineExceeded, twerr.Code())
+
+	resp, err := method(rpcmethod.WithName(ctx, "bakins.todo.v1.TodoService/ListTasks"), nil)
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp)
+}
@@ -0,0 +1,105 @@
+package todo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/twitchtv/twirp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/bakins/twirp-todo-example/internal/auth"
+	pb "github.com/bakins/twirp-todo-example/proto"
+)
+
+// AuditImported is the audit action for tasks added by ImportHandler. Imports
+// do not call hooks, so it is not an event type.
+const AuditImported = "imported"
+
+// insertAudit records a change to a task within tx, so the entry is only kept
+// if the change is. changes holds only the columns that changed. The actor is
+// the authenticated caller, or empty.
+func (s *Server) insertAudit(ctx context.Context, tx *sql.Tx, taskID uint64, action string, changes map[string]interface{}) error {
+	return s.insertAuditFor(ctx, tx, owner(ctx), taskID, action, changes)
+}
+
+// insertAuditFor is insertAudit for a task owned by taskOwner, which may not be
+// the caller, such as when an admin imports tasks.
+func (s *Server) insertAuditFor(ctx context.Context, tx *sql.Tx, taskOwner string, taskID uint64, action string, changes map[string]interface{}) error {
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit changes %w", err)
+	}
+
+	var actor string
+	if identity, ok := auth.IdentityFromContext(ctx); ok {
+		actor = identity.Subject
+	}
+
+	_, err = s.stmtCache.TxExecContext(ctx, tx, "insert_audit",
+		"insert into {prefix}audit (task_id, owner, actor, action, created, changes) values (?, ?, ?, ?, ?, ?)",
+		taskID, taskOwner, actor, action, time.Now(), string(data))
+
+	return err
+}
+
+// createdChanges returns the audit changes for a new task.
+func createdChanges(title string, description string, tags []string, parentID uint64) map[string]interface{} {
+	changes := map[string]interface{}{
+		"title": title,
+	}
+
+	if description != "" {
+		changes["description"] = description
+	}
+
+	if len(tags) > 0 {
+		changes["tags"] = tags
+	}
+
+	if parentID != 0 {
+		changes["parent_id"] = parentID
+	}
+
+	return changes
+}
+
+// ListAuditEntries returns the changes to a task, oldest first. Entries are
+// kept after a task is deleted.
+func (s *Server) ListAuditEntries(ctx context.Context, req *pb.ListAuditEntriesRequest) (*pb.ListAuditEntriesResponse, error) {
+	setAttributes(ctx, taskIDKey.Int64(int64(req.TaskId)))
+
+	rows, err := s.stmtCache.QueryContext(ctx, "list_audit",
+		"select id, task_id, action, actor, created, changes from {prefix}audit where owner = ? and task_id = ? order by id",
+		owner(ctx), req.TaskId)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	defer rows.Close()
+
+	var resp pb.ListAuditEntriesResponse
+
+	for rows.Next() {
+		var (
+			entry   pb.AuditEntry
+			created time.Time
+		)
+
+		if err := rows.Scan(&entry.Id, &entry.TaskId, &entry.Action, &entry.Actor, &created, &entry.Changes); err != nil {
+			return nil, twirp.InternalErrorWith(err)
+		}
+
+		entry.Created = timestamppb.New(created)
+
+		resp.Entries = append(resp.Entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	return &resp, nil
+}
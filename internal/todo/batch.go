@@ -0,0 +1,96 @@
+package todo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/twitchtv/twirp"
+
+	pb "github.com/bakins/twirp-todo-example/proto"
+)
+
+// MaxBatchGetTasks is the most ids accepted by BatchGetTasks. Requests are
+// padded to this size so a single prepared statement is used.
+const MaxBatchGetTasks = 100
+
+var batchGetTasksQuery = "select " + taskColumns + " from {prefix}tasks where owner = ? and deleted_at is null and id in (" +
+	strings.TrimSuffix(strings.Repeat("?, ", MaxBatchGetTasks), ", ") +
+	")"
+
+// BatchGetTasks returns the tasks for the requested ids. Ids that do not
+// exist, or have been deleted, are returned in missing rather than as an
+// error.
+func (s *Server) BatchGetTasks(ctx context.Context, req *pb.BatchGetTasksRequest) (*pb.BatchGetTasksResponse, error) {
+	if len(req.Ids) > MaxBatchGetTasks {
+		return nil, twirp.InvalidArgumentError("ids", fmt.Sprintf("must have at most %d entries", MaxBatchGetTasks))
+	}
+
+	// ids start at 1, so the padding never matches a task
+	args := make([]interface{}, MaxBatchGetTasks+1)
+	args[0] = owner(ctx)
+
+	for i := 1; i < len(args); i++ {
+		args[i] = uint64(0)
+	}
+
+	for i, id := range req.Ids {
+		args[i+1] = id
+	}
+
+	rows, err := s.stmtCache.QueryContext(ctx, "batch_get_tasks", batchGetTasksQuery, args...)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	defer rows.Close()
+
+	found := make(map[uint64]*pb.Task, len(req.Ids))
+
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, twirp.InternalErrorWith(err)
+		}
+
+		found[task.Id] = task
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	// release the connection before reading tags
+	_ = rows.Close()
+
+	var resp pb.BatchGetTasksResponse
+
+	// results are in the order requested, with duplicates removed.
+	seen := make(map[uint64]bool, len(req.Ids))
+
+	for _, id := range req.Ids {
+		if seen[id] {
+			continue
+		}
+
+		seen[id] = true
+
+		if task, ok := found[id]; ok {
+			resp.Tasks = append(resp.Tasks, task)
+			continue
+		}
+
+		resp.Missing = append(resp.Missing, id)
+	}
+
+	if err := s.loadTags(ctx, resp.Tasks); err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	setAttributes(ctx,
+		tasksCountKey.Int(len(resp.Tasks)),
+		tasksMissingKey.Int(len(resp.Missing)),
+	)
+
+	return &resp, nil
+}
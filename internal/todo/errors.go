@@ -0,0 +1,117 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+	"github.com/twitchtv/twirp"
+	"go.uber.org/zap"
+
+	"github.com/bakins/twirp-todo-example/internal/logging"
+)
+
+// notFound returns a not_found error with metadata identifying the task, so
+// clients do not need to parse the message.
+func notFound(id uint64) twirp.Error {
+	return twirp.NotFound.Errorf("task %d not found", id).
+		WithMeta("resource", "task").
+		WithMeta("id", strconv.FormatUint(id, 10))
+}
+
+// versionConflict returns a failed_precondition error for a task that has
+// changed since the client read it. The current version is included so the
+// client does not need to parse the message.
+func versionConflict(id uint64, version uint64) twirp.Error {
+	return twirp.FailedPrecondition.Errorf("task %d has been changed, its version is %d", id, version).
+		WithMeta("resource", "task").
+		WithMeta("id", strconv.FormatUint(id, 10)).
+		WithMeta("version", strconv.FormatUint(version, 10))
+}
+
+// isUniqueViolation returns true if err is caused by a unique constraint.
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505"
+	}
+
+	return false
+}
+
+// isReadOnly returns true if err is caused by writing to a read-only database.
+func isReadOnly(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrReadonly
+	}
+
+	// read_only_sql_transaction
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "25006"
+	}
+
+	return false
+}
+
+// sqliteConstraints names sqlite constraint errors as postgres does, so the
+// constraint metadata is the same for both drivers.
+var sqliteConstraints = map[sqlite3.ErrNoExtended]string{
+	sqlite3.ErrConstraintCheck:      "check_violation",
+	sqlite3.ErrConstraintForeignKey: "foreign_key_violation",
+	sqlite3.ErrConstraintNotNull:    "not_null_violation",
+	sqlite3.ErrConstraintPrimaryKey: "unique_violation",
+	sqlite3.ErrConstraintUnique:     "unique_violation",
+}
+
+// writeError converts an error from a write to a twirp error. Constraint
+// violations are the caller's fault, so they are reported as
+// failed_precondition with the kind of constraint, such as unique_violation,
+// in the metadata. The database's message may include table and column
+// names, so it is logged rather than returned. Writes to a read-only
+// database are also failed_precondition, as retrying will not help. Twirp
+// errors are returned as is.
+func writeError(ctx context.Context, err error) twirp.Error {
+	var twerr twirp.Error
+	if errors.As(err, &twerr) {
+		return twerr
+	}
+
+	if isReadOnly(err) {
+		return twirp.NewError(twirp.FailedPrecondition, "database is read-only")
+	}
+
+	var constraint string
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code == sqlite3.ErrConstraint {
+		constraint = sqliteConstraints[sqliteErr.ExtendedCode]
+		if constraint == "" {
+			constraint = "integrity_constraint_violation"
+		}
+	}
+
+	// class 23 is integrity constraint violations
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code.Class() == "23" {
+		constraint = pqErr.Code.Name()
+	}
+
+	if constraint == "" {
+		return twirp.InternalErrorWith(err)
+	}
+
+	logging.Warn(ctx, "constraint violation", zap.String("constraint", constraint), zap.Error(err))
+
+	return twirp.NewError(twirp.FailedPrecondition, "constraint violation").
+		WithMeta("resource", "task").
+		WithMeta("constraint", constraint)
+}
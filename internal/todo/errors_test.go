@@ -0,0 +1,58 @@
+package todo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/bakins/twirp-todo-example/internal/logging"
+)
+
+func TestWriteError(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	_, err = db.Exec("create table secret_table (secret_column text not null unique)")
+	require.NoError(t, err)
+
+	_, err = db.Exec("insert into secret_table (secret_column) values ('a')")
+	require.NoError(t, err)
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	ctx := logging.ToContext(context.Background(), zap.New(core))
+
+	tests := map[string]string{
+		"insert into secret_table (secret_column) values (null)": "not_null_violation",
+		"insert into secret_table (secret_column) values ('a')":  "unique_violation",
+	}
+
+	for query, constraint := range tests {
+		_, err := db.Exec(query)
+		require.Error(t, err)
+
+		twerr := writeError(ctx, err)
+		require.Equal(t, twirp.FailedPrecondition, twerr.Code())
+		require.Equal(t, constraint, twerr.Meta("constraint"))
+
+		// the schema is only logged
+		require.NotContains(t, twerr.Msg(), "secret")
+		require.NotContains(t, twerr.Meta("constraint"), "secret")
+	}
+
+	entries := logs.FilterMessage("constraint violation").All()
+	require.Len(t, entries, len(tests))
+	require.Contains(t, entries[0].ContextMap()["error"], "secret_")
+
+	// other errors are internal
+	require.Equal(t, twirp.Internal, writeError(ctx, errors.New("failed")).Code())
+}
@@ -0,0 +1,203 @@
+package todo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bakins/twirp-todo-example/internal/auth"
+	"github.com/bakins/twirp-todo-example/internal/logging"
+)
+
+// ExportedTask is a single task written by ExportHandler.
+type ExportedTask struct {
+	ID          uint64     `json:"id"`
+	Owner       string     `json:"owner"`
+	Created     time.Time  `json:"created"`
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	ParentID    uint64     `json:"parent_id,omitempty"`
+	Deleted     *time.Time `json:"deleted,omitempty"`
+	Archived    *time.Time `json:"archived,omitempty"`
+	// Version is ignored by ImportHandler, as imported tasks are new.
+	Version uint64 `json:"version"`
+}
+
+// exportColumns is the CSV header. Tags are separated by semicolons.
+var exportColumns = []string{"id", "owner", "created", "title", "description", "tags", "parent_id", "deleted", "archived", "version"}
+
+// tags are joined so each task is read with a single query, ordered so a
+// task's rows are adjacent.
+const (
+	exportSelect = "select t.id, t.owner, t.created, t.title, t.description, t.parent_id, t.deleted_at, t.archived_at, t.version, g.name " +
+		"from {prefix}tasks t left join {prefix}task_tags tt on tt.task_id = t.id left join {prefix}tags g on g.id = tt.tag_id "
+	exportOrder = "order by t.id, g.name"
+)
+
+// ExportHandler streams the caller's tasks, including deleted and archived
+// tasks. Admins receive the tasks of every owner. Tasks are written as CSV if
+// the Accept header includes text/csv, otherwise as newline delimited JSON.
+//
+// The caller's identity is read from the request context, so the handler
+// should be wrapped with auth.Require when authentication is enabled.
+func (s *Server) ExportHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		ctx := r.Context()
+
+		var (
+			rows *sql.Rows
+			err  error
+		)
+
+		if auth.IsAdmin(ctx) {
+			rows, err = s.stmtCache.QueryContext(ctx, "export_all_tasks", exportSelect+exportOrder)
+		} else {
+			rows, err = s.stmtCache.QueryContext(ctx, "export_tasks", exportSelect+"where t.owner = ? "+exportOrder, owner(ctx))
+		}
+
+		if err != nil {
+			logging.Error(ctx, "failed to export tasks", zap.Error(err))
+			http.Error(w, "failed to export tasks", http.StatusInternalServerError)
+			return
+		}
+
+		defer rows.Close()
+
+		var write func(*ExportedTask) error
+
+		if strings.Contains(r.Header.Get("Accept"), "text/csv") {
+			w.Header().Set("Content-Type", "text/csv")
+
+			cw := csv.NewWriter(w)
+			defer cw.Flush()
+
+			write = func(t *ExportedTask) error {
+				return cw.Write(t.csvRecord())
+			}
+
+			_ = cw.Write(exportColumns)
+		} else {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+
+			enc := json.NewEncoder(w)
+
+			write = func(t *ExportedTask) error {
+				return enc.Encode(t)
+			}
+		}
+
+		// the status has been sent, so errors can only be logged.
+		if err := exportTasks(ctx, rows, write); err != nil {
+			logging.Error(ctx, "failed to export tasks", zap.Error(err))
+		}
+	})
+}
+
+// exportTasks calls write for each task read from rows.
+func exportTasks(ctx context.Context, rows *sql.Rows, write func(*ExportedTask) error) error {
+	var current *ExportedTask
+
+	for rows.Next() {
+		var (
+			id          uint64
+			taskOwner   string
+			created     sql.NullTime
+			title       sql.NullString
+			description sql.NullString
+			parentID    sql.NullInt64
+			deleted     sql.NullTime
+			archived    sql.NullTime
+			version     uint64
+			tag         sql.NullString
+		)
+
+		if err := rows.Scan(&id, &taskOwner, &created, &title, &description, &parentID, &deleted, &archived, &version, &tag); err != nil {
+			return err
+		}
+
+		if current == nil || current.ID != id {
+			if current != nil {
+				if err := write(current); err != nil {
+					return err
+				}
+			}
+
+			current = &ExportedTask{
+				ID:          id,
+				Owner:       taskOwner,
+				Created:     created.Time,
+				Title:       title.String,
+				Description: description.String,
+				ParentID:    uint64(parentID.Int64),
+				Version:     version,
+			}
+
+			if deleted.Valid {
+				current.Deleted = &deleted.Time
+			}
+
+			if archived.Valid {
+				current.Archived = &archived.Time
+			}
+		}
+
+		if tag.Valid {
+			current.Tags = append(current.Tags, tag.String)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if current != nil {
+		if err := write(current); err != nil {
+			return err
+		}
+	}
+
+	return ctx.Err()
+}
+
+func (t *ExportedTask) csvRecord() []string {
+	record := []string{
+		strconv.FormatUint(t.ID, 10),
+		t.Owner,
+		t.Created.UTC().Format(time.RFC3339Nano),
+		t.Title,
+		t.Description,
+		strings.Join(t.Tags, ";"),
+		"",
+		formatOptionalTime(t.Deleted),
+		formatOptionalTime(t.Archived),
+		strconv.FormatUint(t.Version, 10),
+	}
+
+	if t.ParentID != 0 {
+		record[6] = strconv.FormatUint(t.ParentID, 10)
+	}
+
+	return record
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339Nano)
+}
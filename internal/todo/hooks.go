@@ -0,0 +1,66 @@
+package todo
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/bakins/twirp-todo-example/internal/logging"
+	pb "github.com/bakins/twirp-todo-example/proto"
+)
+
+// Event types.
+const (
+	EventCreated    = "created"
+	EventDeleted    = "deleted"
+	EventRestored   = "restored"
+	EventArchived   = "archived"
+	EventUnarchived = "unarchived"
+	EventReordered  = "reordered"
+)
+
+// Event describes a change to a task. For deletes and reorders only the id of
+// the task is set.
+type Event struct {
+	Type  string
+	Owner string
+	Task  *pb.Task
+}
+
+// Hook is called after a change to a task has been committed. Hooks run in
+// the request's goroutine, so they should not block. Panics are recovered and
+// logged.
+type Hook func(ctx context.Context, e Event)
+
+// WithHook adds a hook that is called after each change to a task. Hooks are
+// called in the order they are added.
+func WithHook(hook Hook) Option {
+	return serverOptionFunc(func(s *Server) error {
+		s.hooks = append(s.hooks, hook)
+
+		return nil
+	})
+}
+
+// notify calls each hook with e, setting its owner from ctx.
+func (s *Server) notify(ctx context.Context, e Event) {
+	e.Owner = owner(ctx)
+
+	for _, hook := range s.hooks {
+		callHook(ctx, hook, e)
+	}
+}
+
+func callHook(ctx context.Context, hook Hook, e Event) {
+	defer func() {
+		if p := recover(); p != nil {
+			logging.Error(ctx, "panic in task hook",
+				zap.String("panic", fmt.Sprint(p)),
+				zap.String("event", e.Type),
+			)
+		}
+	}()
+
+	hook(ctx, e)
+}
@@ -0,0 +1,342 @@
+package todo
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bakins/twirp-todo-example/internal/auth"
+	pb "github.com/bakins/twirp-todo-example/proto"
+)
+
+const (
+	// importBatchSize is the number of tasks inserted per transaction.
+	importBatchSize = 100
+	// maxImportErrors is the most line errors included in an ImportResult.
+	maxImportErrors = 100
+	// maxImportLine is the longest line accepted by ImportHandler.
+	maxImportLine = 1 << 20
+)
+
+// ImportResult is returned by ImportHandler.
+type ImportResult struct {
+	Inserted int           `json:"inserted"`
+	Failed   int           `json:"failed"`
+	Errors   []ImportError `json:"errors,omitempty"`
+	// Error is set if the body could not be read to the end. Tasks before it
+	// may have been inserted.
+	Error string `json:"error,omitempty"`
+}
+
+// ImportError describes a line that was not imported.
+type ImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type importLine struct {
+	number int
+	task   ExportedTask
+}
+
+// importedTask is a task inserted by an import.
+type importedTask struct {
+	id    uint64
+	owner string
+}
+
+// importer tracks the progress of a single import.
+type importer struct {
+	s      *Server
+	result ImportResult
+	// ids maps ids in the import to inserted tasks, so parents are kept.
+	ids map[uint64]importedTask
+}
+
+// ImportHandler reads tasks written by ExportHandler as newline delimited JSON
+// from the body of a POST. Each task is inserted with a new id, keeping its
+// timestamps and tags. Tasks are owned by the caller, and only admins may keep
+// the owner in the import. A parent must appear earlier in the import. Invalid
+// lines, and lines the database rejects, are skipped and reported in the
+// ImportResult. Each task is audited as
+// imported, with the caller as the actor.
+//
+// The caller's identity is read from the request context, so the handler
+// should be wrapped with auth.Require when authentication is enabled. The size
+// of the body should be limited.
+func (s *Server) ImportHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		ctx := r.Context()
+
+		imp := importer{
+			s:   s,
+			ids: map[uint64]importedTask{},
+		}
+
+		scanner := bufio.NewScanner(r.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxImportLine)
+
+		var (
+			batch  []importLine
+			number int
+		)
+
+		for scanner.Scan() {
+			number++
+
+			if len(scanner.Bytes()) == 0 {
+				continue
+			}
+
+			var line importLine
+
+			line.number = number
+
+			if err := json.Unmarshal(scanner.Bytes(), &line.task); err != nil {
+				imp.fail(number, fmt.Errorf("invalid JSON %w", err))
+				continue
+			}
+
+			batch = append(batch, line)
+
+			if len(batch) == importBatchSize {
+				imp.insertBatch(ctx, batch)
+				batch = batch[:0]
+			}
+		}
+
+		imp.insertBatch(ctx, batch)
+
+		status := http.StatusOK
+
+		if err := scanner.Err(); err != nil {
+			imp.result.Error = err.Error()
+			status = http.StatusBadRequest
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(imp.result)
+	})
+}
+
+func (imp *importer) fail(line int, err error) {
+	imp.addError(ImportError{Line: line, Error: err.Error()})
+}
+
+func (imp *importer) addError(e ImportError) {
+	imp.result.Failed++
+
+	if len(imp.result.Errors) < maxImportErrors {
+		imp.result.Errors = append(imp.result.Errors, e)
+	}
+}
+
+// insertBatch inserts lines in a single transaction. Lines that are invalid,
+// or that the database rejects, are skipped. Each line is inserted within a
+// savepoint so a rejected line does not undo the others. If the transaction
+// itself fails, every line in it is counted as failed.
+func (imp *importer) insertBatch(ctx context.Context, batch []importLine) {
+	if len(batch) == 0 {
+		return
+	}
+
+	var (
+		ids      map[uint64]importedTask
+		invalid  []ImportError
+		inserted int
+	)
+
+	err := retry(ctx, func() error {
+		ids = map[uint64]importedTask{}
+		invalid = nil
+		inserted = 0
+
+		tx, err := imp.s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+
+		// a no-op once committed
+		defer func() { _ = tx.Rollback() }()
+
+		for _, line := range batch {
+			if err := imp.validate(ctx, line.task, ids); err != nil {
+				invalid = append(invalid, ImportError{Line: line.number, Error: err.Error()})
+				continue
+			}
+
+			parent, _ := imp.parent(line.task, ids)
+
+			if _, err := tx.ExecContext(ctx, "savepoint import_line"); err != nil {
+				return err
+			}
+
+			task, err := imp.s.importTask(ctx, tx, line.task, parent.id)
+			if err != nil {
+				// the whole batch is retried
+				if isBusy(err) {
+					return fmt.Errorf("line %d %w", line.number, err)
+				}
+
+				if _, err := tx.ExecContext(ctx, "rollback to savepoint import_line"); err != nil {
+					return err
+				}
+
+				invalid = append(invalid, ImportError{Line: line.number, Error: writeError(ctx, err).Msg()})
+
+				continue
+			}
+
+			if _, err := tx.ExecContext(ctx, "release savepoint import_line"); err != nil {
+				return err
+			}
+
+			inserted++
+
+			if line.task.ID != 0 {
+				ids[line.task.ID] = task
+			}
+		}
+
+		return tx.Commit()
+	})
+	if err != nil {
+		for _, line := range batch {
+			imp.fail(line.number, err)
+		}
+
+		return
+	}
+
+	// invalid lines are recorded once the batch is committed, so retries do
+	// not count them twice.
+	for _, e := range invalid {
+		imp.addError(e)
+	}
+
+	for k, v := range ids {
+		imp.ids[k] = v
+	}
+
+	imp.result.Inserted += inserted
+}
+
+// validate applies the same rules as CreateTask. pending holds the tasks
+// inserted by the current batch.
+func (imp *importer) validate(ctx context.Context, task ExportedTask, pending map[uint64]importedTask) error {
+	req := pb.CreateTaskRequest{
+		Title:       task.Title,
+		Description: task.Description,
+		Tags:        task.Tags,
+	}
+
+	if err := validateCreateTask(&req); err != nil {
+		return err
+	}
+
+	if task.ParentID == 0 {
+		return nil
+	}
+
+	parent, ok := imp.parent(task, pending)
+	if !ok {
+		return fmt.Errorf("parent %d was not imported before task %d", task.ParentID, task.ID)
+	}
+
+	// as for CreateTask, a parent must have the same owner
+	if parent.owner != importOwner(ctx, task) {
+		return fmt.Errorf("parent %d of task %d has a different owner", task.ParentID, task.ID)
+	}
+
+	return nil
+}
+
+// parent returns the inserted parent of task, if it has one.
+func (imp *importer) parent(task ExportedTask, pending map[uint64]importedTask) (importedTask, bool) {
+	if task.ParentID == 0 {
+		return importedTask{}, false
+	}
+
+	if parent, ok := pending[task.ParentID]; ok {
+		return parent, true
+	}
+
+	parent, ok := imp.ids[task.ParentID]
+
+	return parent, ok
+}
+
+// importOwner returns the owner of an imported task.
+func importOwner(ctx context.Context, task ExportedTask) string {
+	if task.Owner != "" && auth.IsAdmin(ctx) {
+		return task.Owner
+	}
+
+	return owner(ctx)
+}
+
+// importTask inserts task, with a new id.
+func (s *Server) importTask(ctx context.Context, tx *sql.Tx, task ExportedTask, parentID uint64) (importedTask, error) {
+	taskOwner := importOwner(ctx, task)
+
+	created := task.Created
+	if created.IsZero() {
+		created = time.Now()
+	}
+
+	var parent interface{}
+	if parentID != 0 {
+		parent = parentID
+	}
+
+	rows, err := s.stmtCache.TxQueryContext(
+		ctx,
+		tx,
+		"import_task",
+		"insert into {prefix}tasks (owner, created, title, description, parent_id, deleted_at, archived_at, position) "+
+			"values (?, ?, ?, ?, ?, ?, ?, (select coalesce(max(position), 0) + 1 from {prefix}tasks where owner = ?)) returning id",
+		taskOwner, created, task.Title, task.Description, parent, task.Deleted, task.Archived, taskOwner)
+	if err != nil {
+		return importedTask{}, err
+	}
+
+	var id uint64
+	if rows.Next() {
+		err = rows.Scan(&id)
+	}
+
+	_ = rows.Close()
+
+	if err == nil {
+		err = rows.Err()
+	}
+
+	if err != nil {
+		return importedTask{}, err
+	}
+
+	tags := uniqueTags(task.Tags)
+
+	if err := s.insertTags(ctx, tx, id, tags); err != nil {
+		return importedTask{}, err
+	}
+
+	changes := createdChanges(task.Title, task.Description, tags, parentID)
+
+	if err := s.insertAuditFor(ctx, tx, taskOwner, id, AuditImported, changes); err != nil {
+		return importedTask{}, err
+	}
+
+	return importedTask{id: id, owner: taskOwner}, nil
+}
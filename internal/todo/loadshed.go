@@ -0,0 +1,109 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/twitchtv/twirp"
+)
+
+const (
+	// latencyWindow is how quickly the average forgets old queries. While
+	// no queries run, such as when all requests are shed, the average decays
+	// with this time constant so requests are eventually admitted again.
+	latencyWindow = 10 * time.Second
+	// latencyWeight is the weight of each query in the average.
+	latencyWeight = 0.05
+)
+
+// latencyAverage is an exponentially weighted moving average of query
+// durations.
+type latencyAverage struct {
+	lock    sync.Mutex
+	value   float64
+	updated time.Time
+}
+
+// decay returns value as of now, assuming no queries since it was updated.
+func (a *latencyAverage) decay(now time.Time) float64 {
+	return a.value * math.Exp(-now.Sub(a.updated).Seconds()/latencyWindow.Seconds())
+}
+
+func (a *latencyAverage) add(now time.Time, d time.Duration) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.updated.IsZero() {
+		a.value = float64(d)
+	} else {
+		a.value = a.decay(now)
+		a.value += latencyWeight * (float64(d) - a.value)
+	}
+
+	a.updated = now
+}
+
+func (a *latencyAverage) average(now time.Time) time.Duration {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.updated.IsZero() {
+		return 0
+	}
+
+	return time.Duration(a.decay(now))
+}
+
+// WithLoadShedding rejects requests while the average query duration is at
+// least threshold. See LoadShedding and CheckLatency. Zero, the default,
+// disables load shedding.
+func WithLoadShedding(threshold time.Duration) Option {
+	return serverOptionFunc(func(s *Server) error {
+		if threshold < 0 {
+			return errors.New("load shedding threshold must not be negative")
+		}
+
+		s.shedThreshold = threshold
+
+		return nil
+	})
+}
+
+// overloaded returns an error if the average query duration is over the
+// threshold.
+func (s *Server) overloaded() error {
+	if s.shedThreshold == 0 {
+		return nil
+	}
+
+	if avg := s.latency.average(time.Now()); avg >= s.shedThreshold {
+		return fmt.Errorf("average query duration %s is over %s", avg, s.shedThreshold)
+	}
+
+	return nil
+}
+
+// LoadShedding returns an interceptor that fails requests with unavailable
+// while the database is slow, so it is not given more work. Clients should
+// retry with backoff.
+func (s *Server) LoadShedding() twirp.Interceptor {
+	return func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if err := s.overloaded(); err != nil {
+				return nil, twirp.NewError(twirp.Unavailable, "database is overloaded")
+			}
+
+			return next(ctx, req)
+		}
+	}
+}
+
+// CheckLatency is a health check that fails while requests are being shed,
+// so the instance is taken out of rotation until the database recovers.
+func (s *Server) CheckLatency(ctx context.Context) error {
+	return s.overloaded()
+}
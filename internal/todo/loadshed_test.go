@@ -0,0 +1,64 @@
+package todo
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+)
+
+func TestLatencyAverage(t *testing.T) {
+	var a latencyAverage
+
+	now := time.Now()
+
+	require.Zero(t, a.average(now))
+
+	a.add(now, time.Second)
+	require.Equal(t, time.Second, a.average(now))
+
+	// fast queries bring the average down
+	for i := 0; i < 100; i++ {
+		a.add(now, time.Millisecond)
+	}
+
+	require.Less(t, a.average(now), time.Millisecond*10)
+
+	// the average decays while there are no queries
+	a.add(now, time.Hour)
+	require.Less(t, a.average(now.Add(latencyWindow*10)), a.average(now)/1000)
+}
+
+func TestLoadShedding(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	s, err := New(db, WithLoadShedding(time.Second))
+	require.NoError(t, err)
+
+	defer s.Close()
+
+	method := s.LoadShedding()(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return req, nil
+	})
+
+	ctx := context.Background()
+
+	_, err = method(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, s.CheckLatency(ctx))
+
+	s.latency.add(time.Now(), time.Minute)
+
+	_, err = method(ctx, nil)
+
+	var twerr twirp.Error
+	require.ErrorAs(t, err, &twerr)
+	require.Equal(t, twirp.Unavailable, twerr.Code())
+	require.Error(t, s.CheckLatency(ctx))
+}
@@ -0,0 +1,22 @@
+package todo
+
+import (
+	"context"
+
+	"github.com/bakins/twirp-todo-example/internal/auth"
+)
+
+// DefaultOwner owns the tasks created without an authenticated identity, such
+// as when auth is disabled.
+const DefaultOwner = "local"
+
+// owner returns the owner of tasks read or written with ctx. Tasks belonging to
+// other owners are treated as if they do not exist, so their ids are not
+// leaked.
+func owner(ctx context.Context) string {
+	if identity, ok := auth.IdentityFromContext(ctx); ok && identity.Subject != "" {
+		return identity.Subject
+	}
+
+	return DefaultOwner
+}
@@ -0,0 +1,136 @@
+package todo
+
+import (
+	"context"
+	"database/sql"
+
+	pb "github.com/bakins/twirp-todo-example/proto"
+)
+
+// minPositionGap is the smallest gap between neighbors that a task is moved
+// into. Below this the positions are renumbered, as repeated halving
+// eventually runs out of precision.
+const minPositionGap = 1e-6
+
+// ReorderTask moves a task so it is at the zero based position in the list
+// ordered by position. Positions past the end move the task to the end.
+// Deleted and archived tasks are not counted, so positions match ListTasks
+// when archived tasks are not included.
+func (s *Server) ReorderTask(ctx context.Context, req *pb.ReorderTaskRequest) (*pb.ReorderTaskResponse, error) {
+	setAttributes(ctx, taskIDKey.Int64(int64(req.Id)))
+
+	err := retry(ctx, func() error {
+		return s.reorderTask(ctx, req.Id, int(req.Position), req.ExpectedVersion)
+	})
+	if err != nil {
+		return nil, writeError(ctx, err)
+	}
+
+	s.notify(ctx, Event{Type: EventReordered, Task: &pb.Task{Id: req.Id}})
+
+	return &pb.ReorderTaskResponse{}, nil
+}
+
+type taskPosition struct {
+	id       uint64
+	position float64
+}
+
+func (s *Server) reorderTask(ctx context.Context, id uint64, index int, expectedVersion uint64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	// a no-op once committed
+	defer func() { _ = tx.Rollback() }()
+
+	others, err := s.otherPositions(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if index > len(others) {
+		index = len(others)
+	}
+
+	var position float64
+
+	switch {
+	case len(others) == 0:
+		position = 1
+	case index == 0:
+		position = others[0].position - 1
+	case index == len(others):
+		position = others[index-1].position + 1
+	default:
+		before, after := others[index-1].position, others[index].position
+		if after-before < minPositionGap {
+			if err := s.renumber(ctx, tx, others); err != nil {
+				return err
+			}
+
+			// renumbered positions start at 1
+			before, after = float64(index), float64(index+1)
+		}
+
+		position = before + (after-before)/2
+	}
+
+	result, err := s.stmtCache.TxExecContext(ctx, tx, "reorder_task",
+		"update {prefix}tasks set position = ?, version = version + 1 where owner = ? and id = ? and deleted_at is null"+versionCondition,
+		position, owner(ctx), id, expectedVersion, expectedVersion)
+	if err != nil {
+		return err
+	}
+
+	if err := s.requireUpdated(ctx, tx, result, id, expectedVersion); err != nil {
+		return err
+	}
+
+	if err := s.insertAudit(ctx, tx, id, EventReordered, map[string]interface{}{"position": index}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// otherPositions returns the positions of the owner's visible tasks other
+// than id, in order.
+func (s *Server) otherPositions(ctx context.Context, tx *sql.Tx, id uint64) ([]taskPosition, error) {
+	rows, err := s.stmtCache.TxQueryContext(ctx, tx, "select_positions",
+		"select id, position from {prefix}tasks where owner = ? and id != ? and deleted_at is null and archived_at is null order by position, id",
+		owner(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var positions []taskPosition
+
+	for rows.Next() {
+		var p taskPosition
+		if err := rows.Scan(&p.id, &p.position); err != nil {
+			return nil, err
+		}
+
+		positions = append(positions, p)
+	}
+
+	return positions, rows.Err()
+}
+
+// renumber sets the positions of tasks to 1, 2, 3, and so on.
+func (s *Server) renumber(ctx context.Context, tx *sql.Tx, tasks []taskPosition) error {
+	for i, t := range tasks {
+		_, err := s.stmtCache.TxExecContext(ctx, tx, "renumber_task",
+			"update {prefix}tasks set position = ? where id = ?",
+			float64(i+1), t.id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
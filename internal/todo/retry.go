@@ -0,0 +1,49 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	retryAttempts = 5
+	retryBackoff  = time.Millisecond * 10
+)
+
+// retry calls fn until it succeeds, returns an error other than SQLITE_BUSY or
+// SQLITE_LOCKED, or the attempts are used up. The wait between attempts
+// doubles each time. SQLite only allows a single writer, so busy errors can
+// occur under concurrent writes even with a busy timeout.
+func retry(ctx context.Context, fn func() error) error {
+	backoff := retryBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !isBusy(err) || attempt == retryAttempts {
+			return err
+		}
+
+		t := time.NewTimer(backoff)
+
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+
+		backoff *= 2
+	}
+}
+
+func isBusy(err error) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+
+	return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+}
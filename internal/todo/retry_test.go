@@ -0,0 +1,44 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetry(t *testing.T) {
+	busy := sqlite3.Error{Code: sqlite3.ErrBusy}
+
+	var calls int
+	err := retry(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return busy
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+
+	// other errors are not retried
+	calls = 0
+	other := errors.New("other")
+	err = retry(context.Background(), func() error {
+		calls++
+		return other
+	})
+	require.ErrorIs(t, err, other)
+	require.Equal(t, 1, calls)
+
+	// attempts are limited
+	calls = 0
+	err = retry(context.Background(), func() error {
+		calls++
+		return busy
+	})
+	require.Error(t, err)
+	require.Equal(t, retryAttempts, calls)
+}
@@ -3,26 +3,62 @@ package todo
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/global"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/metric/instrument/syncfloat64"
+	"go.opentelemetry.io/otel/metric/unit"
+	"go.uber.org/zap"
+
+	"github.com/bakins/twirp-todo-example/internal/logging"
 )
 
 type stmtCache struct {
 	lock       sync.RWMutex
 	statements map[string]*sql.Stmt
 	preparer   preparerContext
+	rebind     func(string) string
+	// slowQuery is the duration after which a query is logged. Zero disables
+	// logging.
+	slowQuery time.Duration
+	duration  syncfloat64.Histogram
+	// latency, if set, is updated with the duration of each query.
+	latency *latencyAverage
 }
 
+var queryKey = attribute.Key("query")
+
 type preparerContext interface {
 	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
 }
 
-func newStmtCache(preparer preparerContext) *stmtCache {
+// rebind rewrites queries before they are prepared, such as to change the
+// placeholder style.
+func newStmtCache(preparer preparerContext, rebind func(string) string, slowQuery time.Duration) (*stmtCache, error) {
+	meter := global.Meter("github.com/bakins/twirp-todo-example/internal/todo")
+
+	duration, err := meter.SyncFloat64().Histogram(
+		"db.query.duration",
+		instrument.WithDescription("duration of database queries"),
+		instrument.WithUnit(unit.Milliseconds),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create query duration histogram %w", err)
+	}
+
 	c := stmtCache{
 		statements: make(map[string]*sql.Stmt),
 		preparer:   preparer,
+		rebind:     rebind,
+		slowQuery:  slowQuery,
+		duration:   duration,
 	}
 
-	return &c
+	return &c, nil
 }
 
 func (c *stmtCache) Close() {
@@ -52,25 +88,68 @@ func (c *stmtCache) PrepareContext(ctx context.Context, query string) (*sql.Stmt
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
-	stmt, err := c.preparer.PrepareContext(ctx, query)
+	stmt, err := c.preparer.PrepareContext(ctx, c.rebind(query))
 	if err == nil {
 		c.statements[query] = stmt
 	}
 	return stmt, err
 }
 
-func (c *stmtCache) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+// QueryContext runs a cached statement. label, such as "get_task", identifies
+// the query in metrics and logs, as it does for the other query methods. The
+// time to read the rows is not included.
+func (c *stmtCache) QueryContext(ctx context.Context, label string, query string, args ...interface{}) (*sql.Rows, error) {
 	stmt, err := c.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
+	defer c.observe(ctx, label, time.Now())
 	return stmt.QueryContext(ctx, args...)
 }
 
-func (c *stmtCache) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+func (c *stmtCache) ExecContext(ctx context.Context, label string, query string, args ...interface{}) (sql.Result, error) {
 	stmt, err := c.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
+	defer c.observe(ctx, label, time.Now())
 	return stmt.ExecContext(ctx, args...)
 }
+
+// TxQueryContext runs a cached statement within tx.
+func (c *stmtCache) TxQueryContext(ctx context.Context, tx *sql.Tx, label string, query string, args ...interface{}) (*sql.Rows, error) {
+	stmt, err := c.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer c.observe(ctx, label, time.Now())
+	return tx.StmtContext(ctx, stmt).QueryContext(ctx, args...)
+}
+
+// TxExecContext runs a cached statement within tx.
+func (c *stmtCache) TxExecContext(ctx context.Context, tx *sql.Tx, label string, query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := c.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer c.observe(ctx, label, time.Now())
+	return tx.StmtContext(ctx, stmt).ExecContext(ctx, args...)
+}
+
+// observe records the duration of a query and logs it if it is slow.
+func (c *stmtCache) observe(ctx context.Context, label string, start time.Time) {
+	elapsed := time.Since(start)
+
+	c.duration.Record(ctx, float64(elapsed)/float64(time.Millisecond), queryKey.String(label))
+
+	if c.latency != nil {
+		c.latency.add(time.Now(), elapsed)
+	}
+
+	if c.slowQuery > 0 && elapsed >= c.slowQuery {
+		logging.Warn(ctx, "slow query",
+			zap.String("query", label),
+			zap.Duration("duration", elapsed),
+		)
+	}
+}
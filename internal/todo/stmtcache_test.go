@@ -0,0 +1,51 @@
+package todo
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/bakins/twirp-todo-example/internal/logging"
+)
+
+func TestSlowQuery(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	noop := func(query string) string { return query }
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	ctx := logging.ToContext(context.Background(), zap.New(core))
+
+	// every query is slow
+	c, err := newStmtCache(db, noop, time.Nanosecond)
+	require.NoError(t, err)
+
+	_, err = c.ExecContext(ctx, "select_one", "select 1")
+	require.NoError(t, err)
+
+	entries := logs.FilterMessage("slow query").All()
+	require.Len(t, entries, 1)
+	require.Equal(t, "select_one", entries[0].ContextMap()["query"])
+
+	c.Close()
+
+	// disabled
+	c, err = newStmtCache(db, noop, 0)
+	require.NoError(t, err)
+
+	defer c.Close()
+
+	_, err = c.ExecContext(ctx, "select_one", "select 1")
+	require.NoError(t, err)
+	require.Len(t, logs.FilterMessage("slow query").All(), 1)
+}
@@ -0,0 +1,140 @@
+package todo
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+
+	pb "github.com/bakins/twirp-todo-example/proto"
+)
+
+// tagBatchSize is the number of task ids read per query when loading tags.
+// Batches are padded to this size so a single prepared statement is used.
+const tagBatchSize = 100
+
+var selectTagsQuery = "select tt.task_id, t.name from {prefix}task_tags tt join {prefix}tags t on t.id = tt.tag_id where tt.task_id in (" +
+	strings.TrimSuffix(strings.Repeat("?, ", tagBatchSize), ", ") +
+	") order by t.name"
+
+// loadTags sets the tags for each of tasks.
+func (s *Server) loadTags(ctx context.Context, tasks []*pb.Task) error {
+	byID := make(map[uint64]*pb.Task, len(tasks))
+	for _, t := range tasks {
+		byID[t.Id] = t
+	}
+
+	for start := 0; start < len(tasks); start += tagBatchSize {
+		end := start + tagBatchSize
+		if end > len(tasks) {
+			end = len(tasks)
+		}
+
+		// ids start at 1, so the padding never matches a task
+		args := make([]interface{}, tagBatchSize)
+		for i := range args {
+			args[i] = uint64(0)
+		}
+
+		for i, t := range tasks[start:end] {
+			args[i] = t.Id
+		}
+
+		if err := s.loadTagBatch(ctx, byID, args); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) loadTagBatch(ctx context.Context, byID map[uint64]*pb.Task, args []interface{}) error {
+	rows, err := s.stmtCache.QueryContext(ctx, "select_tags", selectTagsQuery, args...)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id   uint64
+			name string
+		)
+
+		if err := rows.Scan(&id, &name); err != nil {
+			return err
+		}
+
+		if t, ok := byID[id]; ok {
+			t.Tags = append(t.Tags, name)
+		}
+	}
+
+	return rows.Err()
+}
+
+// insertTags adds tags to a task, creating any tags that do not exist.
+func (s *Server) insertTags(ctx context.Context, tx *sql.Tx, taskID uint64, tags []string) error {
+	for _, name := range tags {
+		tagID, err := s.upsertTag(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+
+		if _, err := s.stmtCache.TxExecContext(ctx, tx, "insert_task_tag",
+			"insert into {prefix}task_tags (task_id, tag_id) values (?, ?)",
+			taskID, tagID,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// upsertTag returns the id of the tag, creating it if needed. The update is
+// a no-op that allows returning the id of an existing tag.
+func (s *Server) upsertTag(ctx context.Context, tx *sql.Tx, name string) (uint64, error) {
+	rows, err := s.stmtCache.TxQueryContext(ctx, tx, "upsert_tag",
+		"insert into {prefix}tags (name) values (?) on conflict (name) do update set name = excluded.name returning id",
+		name,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rows.Close()
+
+	var id uint64
+	if rows.Next() {
+		if err := rows.Scan(&id); err != nil {
+			return 0, err
+		}
+	}
+
+	return id, rows.Err()
+}
+
+// uniqueTags returns tags sorted with duplicates removed.
+func uniqueTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(tags))
+	out := make([]string, 0, len(tags))
+
+	for _, t := range tags {
+		if seen[t] {
+			continue
+		}
+
+		seen[t] = true
+		out = append(out, t)
+	}
+
+	sort.Strings(out)
+
+	return out
+}
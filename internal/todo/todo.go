@@ -3,38 +3,169 @@ package todo
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/twitchtv/twirp"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
-	pb "github.com/bakins/twirp-todo-example/internal/proto"
+	"github.com/bakins/twirp-todo-example/internal/auth"
+	"github.com/bakins/twirp-todo-example/internal/database"
+	pb "github.com/bakins/twirp-todo-example/proto"
 )
 
 type Server struct {
 	db        *sql.DB
 	stmtCache *stmtCache
+	driver    string
+	slowQuery time.Duration
+	prefix    string
+	// latency is the average query duration, used for load shedding.
+	latency       *latencyAverage
+	shedThreshold time.Duration
+	broker        *broker
+	hooks         []Hook
 }
 
 var _ pb.TodoService = &Server{}
 
-func New(db *sql.DB) (*Server, error) {
+type Option interface {
+	apply(*Server) error
+}
+
+type serverOptionFunc func(*Server) error
+
+func (f serverOptionFunc) apply(s *Server) error {
+	return f(s)
+}
+
+// WithDriver sets the database driver, which controls the SQL dialect.
+// The default is database.SQLite.
+func WithDriver(driver string) Option {
+	return serverOptionFunc(func(s *Server) error {
+		switch driver {
+		case "":
+			driver = database.SQLite
+		case database.SQLite, database.Postgres:
+		default:
+			return fmt.Errorf("unsupported database driver %q", driver)
+		}
+
+		s.driver = driver
+
+		return nil
+	})
+}
+
+// WithSlowQueryThreshold logs a warning for queries that take at least d.
+// Zero, the default, disables the log.
+func WithSlowQueryThreshold(d time.Duration) Option {
+	return serverOptionFunc(func(s *Server) error {
+		if d < 0 {
+			return errors.New("slow query threshold must not be negative")
+		}
+
+		s.slowQuery = d
+
+		return nil
+	})
+}
+
+// WithTablePrefix sets the prefix of table names, which must match
+// database.Config.TablePrefix.
+func WithTablePrefix(prefix string) Option {
+	return serverOptionFunc(func(s *Server) error {
+		if err := database.ValidateTablePrefix(prefix); err != nil {
+			return err
+		}
+
+		s.prefix = prefix
+
+		return nil
+	})
+}
+
+func New(db *sql.DB, options ...Option) (*Server, error) {
 	s := Server{
-		db:        db,
-		stmtCache: newStmtCache(db),
+		db:      db,
+		driver:  database.SQLite,
+		latency: &latencyAverage{},
+		broker:  newBroker(),
 	}
 
+	for _, o := range options {
+		if err := o.apply(&s); err != nil {
+			return nil, fmt.Errorf("failed to create todo server %w", err)
+		}
+	}
+
+	// watchers are a hook like any other
+	s.hooks = append(s.hooks, s.broker.publish)
+
+	c, err := newStmtCache(db, func(query string) string {
+		return database.Rebind(s.driver, database.ApplyTablePrefix(s.prefix, query))
+	}, s.slowQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create todo server %w", err)
+	}
+
+	c.latency = s.latency
+	s.stmtCache = c
+
 	return &s, nil
 }
 
 func (s *Server) Close() {
+	s.broker.close()
 	s.stmtCache.Close()
 }
 
+// listOrders maps the accepted ListTasks order_by values to SQL. User input is
+// never added to a query directly. Each ordering is a separate prepared
+// statement.
+var listOrders = map[string]string{
+	"":             "id",
+	"id_asc":       "id",
+	"id_desc":      "id desc",
+	"created_asc":  "created, id",
+	"created_desc": "created desc, id desc",
+	"title_asc":    "title, id",
+	"title_desc":   "title desc, id desc",
+	"position_asc": "position, id",
+}
+
 func (s *Server) ListTasks(ctx context.Context, req *pb.ListTasksRequest) (*pb.ListTasksResponse, error) {
-	rows, err := s.stmtCache.QueryContext(ctx,
-		"select id, created, title, description from tasks order by id",
-	)
+	order, ok := listOrders[req.OrderBy]
+	if !ok {
+		return nil, twirp.InvalidArgumentError("order_by", "is not a supported ordering")
+	}
+
+	conditions := []string{"owner = ?"}
+	args := []interface{}{owner(ctx)}
+
+	if !req.IncludeDeleted {
+		conditions = append(conditions, "deleted_at is null")
+	}
+
+	if !req.IncludeArchived {
+		conditions = append(conditions, "archived_at is null")
+	}
+
+	if req.ParentId != 0 {
+		conditions = append(conditions, "parent_id = ?")
+		args = append(args, req.ParentId)
+	}
+
+	if req.Tag != "" {
+		conditions = append(conditions, "id in (select tt.task_id from {prefix}task_tags tt join {prefix}tags t on t.id = tt.tag_id where t.name = ?)")
+		args = append(args, req.Tag)
+	}
+
+	query := "select " + taskColumns + " from {prefix}tasks where " + strings.Join(conditions, " and ")
+
+	rows, err := s.stmtCache.QueryContext(ctx, "list_tasks", query+" order by "+order, args...)
 	if err != nil {
 		// TODO: map sql error to more fitting twirp error
 		return nil, twirp.InternalErrorWith(err)
@@ -44,66 +175,285 @@ func (s *Server) ListTasks(ctx context.Context, req *pb.ListTasksRequest) (*pb.L
 	var resp pb.ListTasksResponse
 
 	for rows.Next() {
-		var (
-			id          uint64
-			created     sql.NullTime
-			title       sql.NullString
-			description sql.NullString
-		)
-
-		if err := rows.Scan(&id, &created, &title, &description); err != nil {
+		task, err := scanTask(rows)
+		if err != nil {
 			// TODO: map sql error to more fitting twirp error
 			return nil, twirp.InternalErrorWith(err)
 		}
 
-		// it's not an error if any of these are empty
-		task := pb.Task{
-			Id:          id,
-			Created:     timestamppb.New(created.Time),
-			Title:       title.String,
-			Description: description.String,
-		}
+		resp.Tasks = append(resp.Tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
 
-		resp.Tasks = append(resp.Tasks, &task)
+	if err := s.loadTags(ctx, resp.Tasks); err != nil {
+		return nil, twirp.InternalErrorWith(err)
 	}
 
+	setAttributes(ctx, tasksCountKey.Int(len(resp.Tasks)))
+
 	return &resp, nil
 }
 
-func (s *Server) CreateTask(ctx context.Context, req *pb.CreateTaskRequest) (*pb.CreateTaskResponse, error) {
-	created := time.Now()
+// taskColumns are the columns read by scanTask.
+const taskColumns = "id, created, title, description, deleted_at, parent_id, archived_at, version"
 
-	res, err := s.stmtCache.ExecContext(
-		ctx,
-		"insert into tasks (created, title, description) values (?, ?, ?)",
-		created, req.Title, req.Description)
-	if err != nil {
-		// TODO: map sql error to more fitting twirp error
-		return nil, twirp.InternalErrorWith(err)
+func scanTask(rows *sql.Rows) (*pb.Task, error) {
+	var (
+		id          uint64
+		created     sql.NullTime
+		title       sql.NullString
+		description sql.NullString
+		deleted     sql.NullTime
+		parentID    sql.NullInt64
+		archived    sql.NullTime
+		version     uint64
+	)
+
+	if err := rows.Scan(&id, &created, &title, &description, &deleted, &parentID, &archived, &version); err != nil {
+		return nil, err
+	}
+
+	// it's not an error if any of these are empty
+	task := pb.Task{
+		Id:          id,
+		Created:     timestamppb.New(created.Time),
+		Title:       title.String,
+		Description: description.String,
+		ParentId:    uint64(parentID.Int64),
+		Version:     version,
 	}
 
-	// should never get an error. record was inserted, so returning an error to
-	// caller would be misleading
-	id, _ := res.LastInsertId()
+	if deleted.Valid {
+		task.Deleted = timestamppb.New(deleted.Time)
+	}
 
+	if archived.Valid {
+		task.Archived = timestamppb.New(archived.Time)
+	}
+
+	return &task, nil
+}
+
+func (s *Server) CreateTask(ctx context.Context, req *pb.CreateTaskRequest) (*pb.CreateTaskResponse, error) {
 	task := pb.Task{
-		Id:          uint64(id),
-		Created:     timestamppb.New(created),
+		Created:     timestamppb.Now(),
 		Title:       req.Title,
 		Description: req.Description,
+		Tags:        uniqueTags(req.Tags),
+		ParentId:    req.ParentId,
+		Version:     1,
+	}
+
+	err := retry(ctx, func() error {
+		var err error
+		task.Id, err = s.insertTask(ctx, &task, req.IdempotencyKey)
+		return err
+	})
+	if err != nil {
+		if req.IdempotencyKey == "" || !isUniqueViolation(err) {
+			return nil, writeError(ctx, err)
+		}
+
+		// a retry of a request that succeeded returns the original task.
+		existing, findErr := s.findByIdempotencyKey(ctx, req.IdempotencyKey)
+		if findErr != nil {
+			return nil, findErr
+		}
+
+		if existing == nil {
+			return nil, writeError(ctx, err)
+		}
+
+		setAttributes(ctx, taskIDKey.Int64(int64(existing.Id)))
+
+		// nothing was created, so there is no event
+		return &pb.CreateTaskResponse{Task: existing}, nil
 	}
 
+	setAttributes(ctx, taskIDKey.Int64(int64(task.Id)))
+
+	s.notify(ctx, Event{Type: EventCreated, Task: &task})
+
 	resp := pb.CreateTaskResponse{
 		Task: &task,
 	}
 
-	return &resp, err
+	return &resp, nil
+}
+
+// findByIdempotencyKey returns the task created with key, including deleted
+// tasks, or nil if there is none.
+func (s *Server) findByIdempotencyKey(ctx context.Context, key string) (*pb.Task, error) {
+	rows, err := s.stmtCache.QueryContext(ctx, "find_idempotency_key",
+		"select "+taskColumns+" from {prefix}tasks where owner = ? and idempotency_key = ?",
+		owner(ctx), key)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, twirp.InternalErrorWith(err)
+		}
+
+		return nil, nil
+	}
+
+	task, err := scanTask(rows)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	// release the connection before reading tags
+	_ = rows.Close()
+
+	if err := s.loadTags(ctx, []*pb.Task{task}); err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	return task, nil
 }
 
+// insertTask inserts task, ignoring its id, and returns the new id. An empty
+// idempotency key is stored as null, so it is not unique.
+func (s *Server) insertTask(ctx context.Context, task *pb.Task, idempotencyKey string) (uint64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	// a no-op once committed
+	defer func() { _ = tx.Rollback() }()
+
+	var parentID interface{}
+	if task.ParentId != 0 {
+		if err := s.checkParent(ctx, tx, owner(ctx), task.ParentId); err != nil {
+			return 0, err
+		}
+
+		parentID = task.ParentId
+	}
+
+	var key interface{}
+	if idempotencyKey != "" {
+		key = idempotencyKey
+	}
+
+	// postgres does not support LastInsertId, so the id is returned by the
+	// insert itself.
+	rows, err := s.stmtCache.TxQueryContext(
+		ctx,
+		tx,
+		"insert_task",
+		"insert into {prefix}tasks (owner, created, title, description, parent_id, idempotency_key, position) "+
+			"values (?, ?, ?, ?, ?, ?, (select coalesce(max(position), 0) + 1 from {prefix}tasks where owner = ?)) returning id",
+		owner(ctx), task.Created.AsTime(), task.Title, task.Description, parentID, key, owner(ctx))
+	if err != nil {
+		return 0, err
+	}
+
+	var id uint64
+	if rows.Next() {
+		err = rows.Scan(&id)
+	}
+
+	_ = rows.Close()
+
+	if err == nil {
+		err = rows.Err()
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.insertTags(ctx, tx, id, task.Tags); err != nil {
+		return 0, err
+	}
+
+	changes := createdChanges(task.Title, task.Description, task.Tags, task.ParentId)
+
+	if err := s.insertAudit(ctx, tx, id, EventCreated, changes); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// checkParent returns an invalid_argument error if the parent task does not
+// exist, is deleted, or belongs to another owner. The foreign key only prevents
+// the first.
+func (s *Server) checkParent(ctx context.Context, tx *sql.Tx, owner string, parentID uint64) error {
+	rows, err := s.stmtCache.TxQueryContext(ctx, tx, "check_parent",
+		"select 1 from {prefix}tasks where owner = ? and id = ? and deleted_at is null",
+		owner, parentID)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		return twirp.InvalidArgumentError("parent_id", fmt.Sprintf("task %d does not exist", parentID))
+	}
+
+	return nil
+}
+
+// GetTask returns a task. Deleted tasks are only returned to admins that set
+// include_hidden, so they can be found before being restored.
 func (s *Server) GetTask(ctx context.Context, req *pb.GetTaskRequest) (*pb.GetTaskResponse, error) {
-	rows, err := s.stmtCache.QueryContext(ctx,
-		"select id, created, title, description from tasks where id = ?",
-		req.Id)
+	setAttributes(ctx, taskIDKey.Int64(int64(req.Id)))
+
+	if req.IncludeHidden {
+		if !auth.IsAdmin(ctx) {
+			return nil, twirp.NewError(twirp.PermissionDenied, "include_hidden requires an admin")
+		}
+
+		task, err := s.getHiddenTask(ctx, req.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		return &pb.GetTaskResponse{Task: task}, nil
+	}
+
+	task, err := s.getTask(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := pb.GetTaskResponse{
+		Task: task,
+	}
+
+	return &resp, nil
+}
+
+// getTask returns a task that has not been deleted.
+func (s *Server) getTask(ctx context.Context, id uint64) (*pb.Task, error) {
+	return s.readTask(ctx, id, "get_task",
+		"select "+taskColumns+" from {prefix}tasks where owner = ? and id = ? and deleted_at is null")
+}
+
+// getHiddenTask returns a task even if it has been deleted.
+func (s *Server) getHiddenTask(ctx context.Context, id uint64) (*pb.Task, error) {
+	return s.readTask(ctx, id, "get_hidden_task",
+		"select "+taskColumns+" from {prefix}tasks where owner = ? and id = ?")
+}
+
+// readTask runs query, which selects a task by owner and id.
+func (s *Server) readTask(ctx context.Context, id uint64, label string, query string) (*pb.Task, error) {
+	rows, err := s.stmtCache.QueryContext(ctx, label, query, owner(ctx), id)
 	if err != nil {
 		return nil, twirp.InternalErrorWith(err)
 	}
@@ -111,30 +461,206 @@ func (s *Server) GetTask(ctx context.Context, req *pb.GetTaskRequest) (*pb.GetTa
 	defer rows.Close()
 
 	if !rows.Next() {
-		return nil, twirp.NotFound.Errorf("task %d not found", req.Id)
+		if err := rows.Err(); err != nil {
+			return nil, twirp.InternalErrorWith(err)
+		}
+
+		return nil, notFound(id)
 	}
-	var (
-		id          uint64
-		created     sql.NullTime
-		title       sql.NullString
-		description sql.NullString
-	)
 
-	if err := rows.Scan(&id, &created, &title, &description); err != nil {
+	task, err := scanTask(rows)
+	if err != nil {
 		// TODO: map sql error to more fitting twirp error
 		return nil, twirp.InternalErrorWith(err)
 	}
 
-	task := pb.Task{
-		Id:          id,
-		Created:     timestamppb.New(created.Time),
-		Title:       title.String,
-		Description: description.String,
+	// release the connection before reading tags
+	_ = rows.Close()
+
+	if err := s.loadTags(ctx, []*pb.Task{task}); err != nil {
+		return nil, twirp.InternalErrorWith(err)
 	}
 
-	resp := pb.GetTaskResponse{
-		Task: &task,
+	return task, nil
+}
+
+// DeleteTask marks a task as deleted. Deleted tasks are hidden from reads but
+// may be restored.
+func (s *Server) DeleteTask(ctx context.Context, req *pb.DeleteTaskRequest) (*pb.DeleteTaskResponse, error) {
+	setAttributes(ctx, taskIDKey.Int64(int64(req.Id)))
+
+	now := time.Now()
+
+	err := s.updateTask(ctx, req.Id, req.ExpectedVersion, EventDeleted, map[string]interface{}{"deleted_at": now}, "delete_task",
+		"update {prefix}tasks set deleted_at = ?, version = version + 1 where owner = ? and id = ? and deleted_at is null",
+		now, owner(ctx), req.Id)
+	if err != nil {
+		return nil, writeError(ctx, err)
+	}
+
+	s.notify(ctx, Event{Type: EventDeleted, Task: &pb.Task{Id: req.Id}})
+
+	return &pb.DeleteTaskResponse{}, nil
+}
+
+// RestoreTask undoes DeleteTask.
+func (s *Server) RestoreTask(ctx context.Context, req *pb.RestoreTaskRequest) (*pb.RestoreTaskResponse, error) {
+	setAttributes(ctx, taskIDKey.Int64(int64(req.Id)))
+
+	err := s.updateTask(ctx, req.Id, req.ExpectedVersion, EventRestored, map[string]interface{}{"deleted_at": nil}, "restore_task",
+		"update {prefix}tasks set deleted_at = null, version = version + 1 where owner = ? and id = ? and deleted_at is not null",
+		owner(ctx), req.Id)
+	if err != nil {
+		return nil, writeError(ctx, err)
+	}
+
+	task, err := s.getTask(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify(ctx, Event{Type: EventRestored, Task: task})
+
+	resp := pb.RestoreTaskResponse{
+		Task: task,
+	}
+
+	return &resp, nil
+}
+
+// ArchiveTask hides a task from ListTasks unless include_archived is set.
+// Unlike DeleteTask, archived tasks may still be read directly.
+func (s *Server) ArchiveTask(ctx context.Context, req *pb.ArchiveTaskRequest) (*pb.ArchiveTaskResponse, error) {
+	setAttributes(ctx, taskIDKey.Int64(int64(req.Id)))
+
+	now := time.Now()
+
+	err := s.updateTask(ctx, req.Id, req.ExpectedVersion, EventArchived, map[string]interface{}{"archived_at": now}, "archive_task",
+		"update {prefix}tasks set archived_at = ?, version = version + 1 where owner = ? and id = ? and deleted_at is null and archived_at is null",
+		now, owner(ctx), req.Id)
+	if err != nil {
+		return nil, writeError(ctx, err)
+	}
+
+	task, err := s.getTask(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify(ctx, Event{Type: EventArchived, Task: task})
+
+	resp := pb.ArchiveTaskResponse{
+		Task: task,
 	}
 
 	return &resp, nil
 }
+
+// UnarchiveTask undoes ArchiveTask.
+func (s *Server) UnarchiveTask(ctx context.Context, req *pb.UnarchiveTaskRequest) (*pb.UnarchiveTaskResponse, error) {
+	setAttributes(ctx, taskIDKey.Int64(int64(req.Id)))
+
+	err := s.updateTask(ctx, req.Id, req.ExpectedVersion, EventUnarchived, map[string]interface{}{"archived_at": nil}, "unarchive_task",
+		"update {prefix}tasks set archived_at = null, version = version + 1 where owner = ? and id = ? and deleted_at is null and archived_at is not null",
+		owner(ctx), req.Id)
+	if err != nil {
+		return nil, writeError(ctx, err)
+	}
+
+	task, err := s.getTask(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify(ctx, Event{Type: EventUnarchived, Task: task})
+
+	resp := pb.UnarchiveTaskResponse{
+		Task: task,
+	}
+
+	return &resp, nil
+}
+
+// versionCondition is added to an update so it only changes the expected
+// version of a task. An expected version of zero matches any version.
+const versionCondition = " and (? = 0 or version = ?)"
+
+// updateTask runs query, which updates the task id, and records an audit
+// entry with changes in the same transaction. The update is limited by
+// versionCondition, so query must end with its where clause. A not found or
+// failed precondition error is returned if the task was not updated.
+func (s *Server) updateTask(ctx context.Context, id uint64, expectedVersion uint64, action string, changes map[string]interface{}, label string, query string, args ...interface{}) error {
+	query += versionCondition
+	args = append(args, expectedVersion, expectedVersion)
+
+	return retry(ctx, func() error {
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+
+		// a no-op once committed
+		defer func() { _ = tx.Rollback() }()
+
+		result, err := s.stmtCache.TxExecContext(ctx, tx, label, query, args...)
+		if err != nil {
+			return err
+		}
+
+		if err := s.requireUpdated(ctx, tx, result, id, expectedVersion); err != nil {
+			return err
+		}
+
+		if err := s.insertAudit(ctx, tx, id, action, changes); err != nil {
+			return err
+		}
+
+		return tx.Commit()
+	})
+}
+
+// requireUpdated returns an error if no rows were changed. If the task id
+// exists with a version other than expectedVersion the error is a failed
+// precondition, otherwise it is not found.
+func (s *Server) requireUpdated(ctx context.Context, tx *sql.Tx, result sql.Result, id uint64, expectedVersion uint64) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return twirp.InternalErrorWith(err)
+	}
+
+	if n != 0 {
+		return nil
+	}
+
+	if expectedVersion == 0 {
+		return notFound(id)
+	}
+
+	rows, err := s.stmtCache.TxQueryContext(ctx, tx, "select_version",
+		"select version from {prefix}tasks where owner = ? and id = ?",
+		owner(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		return notFound(id)
+	}
+
+	var version uint64
+	if err := rows.Scan(&version); err != nil {
+		return err
+	}
+
+	if version != expectedVersion {
+		return versionConflict(id, version)
+	}
+
+	return notFound(id)
+}
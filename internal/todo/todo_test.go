@@ -1,71 +1,41 @@
 package todo_test
 
 import (
+	"bufio"
 	"context"
-	"io/fs"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
-	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
+	"github.com/bakins/twirp-todo-example/internal/auth"
 	"github.com/bakins/twirp-todo-example/internal/database"
-	pb "github.com/bakins/twirp-todo-example/internal/proto"
+	"github.com/bakins/twirp-todo-example/internal/httpserver"
 	"github.com/bakins/twirp-todo-example/internal/todo"
+	"github.com/bakins/twirp-todo-example/internal/todotest"
+	pb "github.com/bakins/twirp-todo-example/proto"
+	"github.com/bakins/twirp-todo-example/schema"
 )
 
 func TestServer(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 	defer cancel()
 
-	defer func() {
-		walk := func(path string, info fs.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-			if !strings.Contains(path, "testing.db") {
-				return nil
-			}
-
-			err = os.Remove(path)
-			assert.NoError(t, err)
-
-			return nil
-		}
-
-		err := filepath.Walk("data", walk)
-		assert.NoError(t, err)
-	}()
-
-	cwd, err := os.Getwd()
-	require.NoError(t, err)
-
-	dbfile := filepath.Join("data", "testing.db")
-
-	cfg := database.Config{
-		SchemaDirectory: filepath.Join(filepath.Dir(filepath.Dir(cwd)), "schema"),
-		Filename:        dbfile,
-	}
-
-	db, err := cfg.Build(ctx)
-	require.NoError(t, err)
-
-	defer db.Close()
-
-	s, err := todo.New(db)
-	require.NoError(t, err)
-
-	defer s.Close()
-
-	svr := httptest.NewServer(pb.NewTodoServiceServer(s))
-	defer svr.Close()
-
-	client := pb.NewTodoServiceProtobufClient(svr.URL, http.DefaultClient)
+	h := todotest.New(t)
+	s, client := h.Server, h.Client
 
 	t.Run("create task", func(t *testing.T) {
 		for i := 0; i < 10; i++ {
@@ -99,6 +69,500 @@ func TestServer(t *testing.T) {
 		}
 	})
 
+	t.Run("list tasks order", func(t *testing.T) {
+		resp, err := client.ListTasks(
+			ctx,
+			&pb.ListTasksRequest{
+				OrderBy: "id_desc",
+			},
+		)
+
+		require.NoError(t, err)
+
+		require.Len(t, resp.Tasks, 10)
+		require.Equal(t, uint64(10), resp.Tasks[0].Id)
+
+		_, err = client.ListTasks(
+			ctx,
+			&pb.ListTasksRequest{
+				OrderBy: "id; drop table tasks",
+			},
+		)
+
+		requireCode(t, twirp.InvalidArgument, err)
+	})
+
+	t.Run("tags", func(t *testing.T) {
+		created, err := client.CreateTask(
+			ctx,
+			&pb.CreateTaskRequest{
+				Title: "tagged",
+				Tags:  []string{"work", "urgent", "work"},
+			},
+		)
+
+		require.NoError(t, err)
+		require.Equal(t, []string{"urgent", "work"}, created.Task.Tags)
+
+		resp, err := client.GetTask(
+			ctx,
+			&pb.GetTaskRequest{
+				Id: created.Task.Id,
+			},
+		)
+
+		require.NoError(t, err)
+		require.Equal(t, []string{"urgent", "work"}, resp.Task.Tags)
+
+		list, err := client.ListTasks(
+			ctx,
+			&pb.ListTasksRequest{
+				Tag: "urgent",
+			},
+		)
+
+		require.NoError(t, err)
+		require.Len(t, list.Tasks, 1)
+		require.Equal(t, created.Task.Id, list.Tasks[0].Id)
+		require.Equal(t, []string{"urgent", "work"}, list.Tasks[0].Tags)
+	})
+
+	t.Run("batch get tasks", func(t *testing.T) {
+		resp, err := client.BatchGetTasks(ctx, &pb.BatchGetTasksRequest{Ids: []uint64{3, 1, 99, 3}})
+		require.NoError(t, err)
+
+		require.Len(t, resp.Tasks, 2)
+		require.Equal(t, uint64(3), resp.Tasks[0].Id)
+		require.Equal(t, uint64(1), resp.Tasks[1].Id)
+		require.Equal(t, []uint64{99}, resp.Missing)
+
+		ids := make([]uint64, todo.MaxBatchGetTasks+1)
+		_, err = client.BatchGetTasks(ctx, &pb.BatchGetTasksRequest{Ids: ids})
+		requireCode(t, twirp.InvalidArgument, err)
+	})
+
+	t.Run("delete and restore", func(t *testing.T) {
+		_, err := client.DeleteTask(ctx, &pb.DeleteTaskRequest{Id: 2})
+		require.NoError(t, err)
+
+		_, err = client.GetTask(ctx, &pb.GetTaskRequest{Id: 2})
+		requireCode(t, twirp.NotFound, err)
+
+		var twerr twirp.Error
+		require.True(t, errors.As(err, &twerr))
+		require.Equal(t, "task", twerr.Meta("resource"))
+		require.Equal(t, "2", twerr.Meta("id"))
+
+		// deleting twice is not found
+		_, err = client.DeleteTask(ctx, &pb.DeleteTaskRequest{Id: 2})
+		requireCode(t, twirp.NotFound, err)
+
+		list, err := client.ListTasks(ctx, &pb.ListTasksRequest{})
+		require.NoError(t, err)
+		require.Len(t, list.Tasks, 10)
+
+		list, err = client.ListTasks(ctx, &pb.ListTasksRequest{IncludeDeleted: true})
+		require.NoError(t, err)
+		require.Len(t, list.Tasks, 11)
+		require.NotNil(t, list.Tasks[1].Deleted)
+
+		restored, err := client.RestoreTask(ctx, &pb.RestoreTaskRequest{Id: 2})
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), restored.Task.Id)
+		require.Nil(t, restored.Task.Deleted)
+
+		_, err = client.RestoreTask(ctx, &pb.RestoreTaskRequest{Id: 2})
+		requireCode(t, twirp.NotFound, err)
+	})
+
+	t.Run("include hidden", func(t *testing.T) {
+		_, err := client.DeleteTask(ctx, &pb.DeleteTaskRequest{Id: 2})
+		require.NoError(t, err)
+
+		defer func() {
+			_, err := client.RestoreTask(ctx, &pb.RestoreTaskRequest{Id: 2})
+			require.NoError(t, err)
+		}()
+
+		_, err = client.GetTask(ctx, &pb.GetTaskRequest{Id: 2, IncludeHidden: true})
+		requireCode(t, twirp.PermissionDenied, err)
+
+		admin := auth.WithIdentity(ctx, &auth.Identity{Subject: todo.DefaultOwner, Admin: true})
+
+		resp, err := s.GetTask(admin, &pb.GetTaskRequest{Id: 2, IncludeHidden: true})
+		require.NoError(t, err)
+		require.NotNil(t, resp.Task.Deleted)
+	})
+
+	t.Run("archive", func(t *testing.T) {
+		before, err := client.GetTask(ctx, &pb.GetTaskRequest{Id: 3})
+		require.NoError(t, err)
+
+		archived, err := client.ArchiveTask(ctx, &pb.ArchiveTaskRequest{Id: 3})
+		require.NoError(t, err)
+		require.NotNil(t, archived.Task.Archived)
+		require.Equal(t, before.Task.Version+1, archived.Task.Version)
+
+		// archived tasks may still be read directly
+		got, err := client.GetTask(ctx, &pb.GetTaskRequest{Id: 3})
+		require.NoError(t, err)
+		require.NotNil(t, got.Task.Archived)
+
+		_, err = client.ArchiveTask(ctx, &pb.ArchiveTaskRequest{Id: 3})
+		requireCode(t, twirp.NotFound, err)
+
+		list, err := client.ListTasks(ctx, &pb.ListTasksRequest{})
+		require.NoError(t, err)
+		require.Len(t, list.Tasks, 10)
+
+		list, err = client.ListTasks(ctx, &pb.ListTasksRequest{IncludeArchived: true})
+		require.NoError(t, err)
+		require.Len(t, list.Tasks, 11)
+
+		unarchived, err := client.UnarchiveTask(ctx, &pb.UnarchiveTaskRequest{Id: 3})
+		require.NoError(t, err)
+		require.Nil(t, unarchived.Task.Archived)
+
+		_, err = client.UnarchiveTask(ctx, &pb.UnarchiveTaskRequest{Id: 3})
+		requireCode(t, twirp.NotFound, err)
+	})
+
+	t.Run("expected version", func(t *testing.T) {
+		before, err := client.GetTask(ctx, &pb.GetTaskRequest{Id: 4})
+		require.NoError(t, err)
+
+		version := before.Task.Version
+
+		// a conflicting update reports the current version
+		_, err = client.ArchiveTask(ctx, &pb.ArchiveTaskRequest{Id: 4, ExpectedVersion: version + 1})
+		requireCode(t, twirp.FailedPrecondition, err)
+
+		var twerr twirp.Error
+		require.True(t, errors.As(err, &twerr))
+		require.Equal(t, strconv.FormatUint(version, 10), twerr.Meta("version"))
+
+		archived, err := client.ArchiveTask(ctx, &pb.ArchiveTaskRequest{Id: 4, ExpectedVersion: version})
+		require.NoError(t, err)
+		require.Equal(t, version+1, archived.Task.Version)
+
+		// the version read before archiving is now stale
+		_, err = client.UnarchiveTask(ctx, &pb.UnarchiveTaskRequest{Id: 4, ExpectedVersion: version})
+		requireCode(t, twirp.FailedPrecondition, err)
+
+		_, err = client.ReorderTask(ctx, &pb.ReorderTaskRequest{Id: 4, ExpectedVersion: version})
+		requireCode(t, twirp.FailedPrecondition, err)
+
+		_, err = client.DeleteTask(ctx, &pb.DeleteTaskRequest{Id: 4, ExpectedVersion: version})
+		requireCode(t, twirp.FailedPrecondition, err)
+
+		_, err = client.UnarchiveTask(ctx, &pb.UnarchiveTaskRequest{Id: 4, ExpectedVersion: archived.Task.Version})
+		require.NoError(t, err)
+
+		// missing tasks are still not found
+		_, err = client.DeleteTask(ctx, &pb.DeleteTaskRequest{Id: 9999, ExpectedVersion: 1})
+		requireCode(t, twirp.NotFound, err)
+	})
+
+	t.Run("reorder", func(t *testing.T) {
+		ids := func() []uint64 {
+			list, err := client.ListTasks(ctx, &pb.ListTasksRequest{OrderBy: "position_asc"})
+			require.NoError(t, err)
+
+			var ids []uint64
+			for _, task := range list.Tasks {
+				ids = append(ids, task.Id)
+			}
+
+			return ids
+		}
+
+		before := ids()
+		require.Greater(t, len(before), 3)
+
+		last := before[len(before)-1]
+
+		_, err := client.ReorderTask(ctx, &pb.ReorderTaskRequest{Id: last, Position: 1})
+		require.NoError(t, err)
+
+		expected := append([]uint64{before[0], last}, before[1:len(before)-1]...)
+		require.Equal(t, expected, ids())
+
+		// moving back and forth between the same neighbors halves the gap
+		// until the list is renumbered.
+		for i := 0; i < 30; i++ {
+			_, err = client.ReorderTask(ctx, &pb.ReorderTaskRequest{Id: expected[2], Position: 1})
+			require.NoError(t, err)
+
+			expected[1], expected[2] = expected[2], expected[1]
+			require.Equal(t, expected, ids())
+		}
+
+		_, err = client.ReorderTask(ctx, &pb.ReorderTaskRequest{Id: expected[0], Position: 1000})
+		require.NoError(t, err)
+
+		expected = append(expected[1:], expected[0])
+		require.Equal(t, expected, ids())
+
+		_, err = client.ReorderTask(ctx, &pb.ReorderTaskRequest{Id: 999})
+		requireCode(t, twirp.NotFound, err)
+	})
+
+	t.Run("reorder archived", func(t *testing.T) {
+		reorderer := auth.WithIdentity(ctx, &auth.Identity{Subject: "reorderer"})
+
+		var created []uint64
+
+		for _, title := range []string{"a", "b", "c", "d"} {
+			resp, err := s.CreateTask(reorderer, &pb.CreateTaskRequest{Title: title})
+			require.NoError(t, err)
+
+			created = append(created, resp.Task.Id)
+		}
+
+		_, err := s.ArchiveTask(reorderer, &pb.ArchiveTaskRequest{Id: created[0]})
+		require.NoError(t, err)
+
+		// the position is within the visible list, which excludes a
+		_, err = s.ReorderTask(reorderer, &pb.ReorderTaskRequest{Id: created[3], Position: 1})
+		require.NoError(t, err)
+
+		list, err := s.ListTasks(reorderer, &pb.ListTasksRequest{OrderBy: "position_asc"})
+		require.NoError(t, err)
+
+		var ids []uint64
+		for _, task := range list.Tasks {
+			ids = append(ids, task.Id)
+		}
+
+		require.Equal(t, []uint64{created[1], created[3], created[2]}, ids)
+	})
+
+	t.Run("export", func(t *testing.T) {
+		exporter := auth.WithIdentity(ctx, &auth.Identity{Subject: "exporter"})
+
+		_, err := s.CreateTask(exporter, &pb.CreateTaskRequest{Title: "exported"})
+		require.NoError(t, err)
+
+		export := httptest.NewServer(auth.Require(auth.FirstOf(
+			auth.StaticToken("user", todo.DefaultOwner),
+			auth.AsAdmin(auth.StaticToken("admin", "admin")),
+		))(s.ExportHandler()))
+		defer export.Close()
+
+		exportTasks := func(token string) []todo.ExportedTask {
+			req, err := http.NewRequest(http.MethodGet, export.URL, nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+
+			defer resp.Body.Close()
+
+			require.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+			var tasks []todo.ExportedTask
+
+			dec := json.NewDecoder(resp.Body)
+			for dec.More() {
+				var task todo.ExportedTask
+				require.NoError(t, dec.Decode(&task))
+				tasks = append(tasks, task)
+			}
+
+			return tasks
+		}
+
+		// only the caller's tasks are exported
+		tasks := exportTasks("user")
+
+		list, err := client.ListTasks(ctx, &pb.ListTasksRequest{IncludeDeleted: true, IncludeArchived: true})
+		require.NoError(t, err)
+		require.Len(t, tasks, len(list.Tasks))
+
+		for i, task := range list.Tasks {
+			require.Equal(t, task.Id, tasks[i].ID)
+			require.Equal(t, task.Title, tasks[i].Title)
+			require.Equal(t, todo.DefaultOwner, tasks[i].Owner)
+		}
+
+		// admins export every owner's tasks
+		var count int
+		require.NoError(t, h.DB.QueryRowContext(ctx, "select count(*) from tasks").Scan(&count))
+
+		all := exportTasks("admin")
+		require.Len(t, all, count)
+		require.Equal(t, "exporter", all[len(all)-1].Owner)
+		require.Equal(t, uint64(1), all[len(all)-1].Version)
+
+		req, err := http.NewRequest(http.MethodGet, export.URL, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", "text/csv")
+		req.Header.Set("Authorization", "Bearer user")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		defer resp.Body.Close()
+
+		records, err := csv.NewReader(resp.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, len(list.Tasks)+1)
+		require.Equal(t, "id", records[0][0])
+	})
+
+	t.Run("import", func(t *testing.T) {
+		imp := httptest.NewServer(auth.Require(auth.FirstOf(
+			auth.StaticToken("user", "importing-user"),
+			auth.AsAdmin(auth.StaticToken("admin", "admin")),
+		))(s.ImportHandler()))
+		defer imp.Close()
+
+		importTasks := func(token string, lines ...string) todo.ImportResult {
+			req, err := http.NewRequest(http.MethodPost, imp.URL, strings.NewReader(strings.Join(lines, "\n")))
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+
+			defer resp.Body.Close()
+
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			var result todo.ImportResult
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+			return result
+		}
+
+		// admins may keep the owner
+		result := importTasks("admin",
+			`{"id": 1000, "owner": "importer", "title": "parent", "tags": ["a"]}`,
+			`{"id": 1001, "owner": "importer", "title": "child", "parent_id": 1000}`,
+			`{"id": 1002, "owner": "importer", "title": ""}`,
+			`not json`,
+			`{"id": 1003, "owner": "importer", "title": "orphan", "parent_id": 999}`,
+			`{"id": 1004, "owner": "someone", "title": "stepchild", "parent_id": 1000}`,
+		)
+
+		require.Equal(t, 2, result.Inserted)
+		require.Equal(t, 4, result.Failed)
+
+		var lines []int
+		for _, e := range result.Errors {
+			lines = append(lines, e.Line)
+		}
+
+		require.ElementsMatch(t, []int{3, 4, 5, 6}, lines)
+
+		// other callers own every task they import
+		result = importTasks("user", `{"owner": "importer", "title": "mine"}`)
+		require.Equal(t, 1, result.Inserted)
+
+		mine, err := s.ListTasks(auth.WithIdentity(ctx, &auth.Identity{Subject: "importing-user"}), &pb.ListTasksRequest{})
+		require.NoError(t, err)
+		require.Len(t, mine.Tasks, 1)
+		require.Equal(t, "mine", mine.Tasks[0].Title)
+
+		importerCtx := auth.WithIdentity(ctx, &auth.Identity{Subject: "importer"})
+
+		tasks, err := s.ListTasks(importerCtx, &pb.ListTasksRequest{})
+		require.NoError(t, err)
+		require.Len(t, tasks.Tasks, 2)
+		require.Equal(t, []string{"a"}, tasks.Tasks[0].Tags)
+		require.Equal(t, tasks.Tasks[0].Id, tasks.Tasks[1].ParentId)
+
+		// imports are audited with the importing admin as the actor
+		audit, err := s.ListAuditEntries(importerCtx, &pb.ListAuditEntriesRequest{TaskId: tasks.Tasks[1].Id})
+		require.NoError(t, err)
+		require.Len(t, audit.Entries, 1)
+		require.Equal(t, todo.AuditImported, audit.Entries[0].Action)
+		require.Equal(t, "admin", audit.Entries[0].Actor)
+		require.Contains(t, audit.Entries[0].Changes, `"parent_id"`)
+	})
+
+	t.Run("watch", func(t *testing.T) {
+		watch, _ := serveWatch(t, s)
+
+		watchCtx, watchCancel := context.WithCancel(ctx)
+		defer watchCancel()
+
+		req, err := http.NewRequestWithContext(watchCtx, http.MethodGet, watch+"/watch", nil)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		_ = resp.Body.Close()
+
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		// gzip would buffer events, so they must not be compressed
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		// the subscription exists once the headers are received
+		resp, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		defer resp.Body.Close()
+
+		require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		require.Empty(t, resp.Header.Get("Content-Encoding"))
+
+		created, err := client.CreateTask(ctx, &pb.CreateTaskRequest{Title: "watched"})
+		require.NoError(t, err)
+
+		_, err = client.DeleteTask(ctx, &pb.DeleteTaskRequest{Id: created.Task.Id})
+		require.NoError(t, err)
+
+		scanner := bufio.NewScanner(resp.Body)
+
+		var lines []string
+		for len(lines) < 6 && scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+
+		require.Len(t, lines, 6)
+		require.Equal(t, "event: created", lines[0])
+		require.Contains(t, lines[1], `"title":"watched"`)
+		require.Equal(t, "event: deleted", lines[3])
+	})
+
+	t.Run("audit", func(t *testing.T) {
+		created, err := client.CreateTask(ctx, &pb.CreateTaskRequest{Title: "audited", Tags: []string{"a"}})
+		require.NoError(t, err)
+
+		id := created.Task.Id
+
+		_, err = client.ArchiveTask(ctx, &pb.ArchiveTaskRequest{Id: id})
+		require.NoError(t, err)
+
+		_, err = client.DeleteTask(ctx, &pb.DeleteTaskRequest{Id: id})
+		require.NoError(t, err)
+
+		// failed changes are not recorded
+		_, err = client.DeleteTask(ctx, &pb.DeleteTaskRequest{Id: id})
+		requireCode(t, twirp.NotFound, err)
+
+		resp, err := client.ListAuditEntries(ctx, &pb.ListAuditEntriesRequest{TaskId: id})
+		require.NoError(t, err)
+		require.Len(t, resp.Entries, 3)
+
+		require.Equal(t, todo.EventCreated, resp.Entries[0].Action)
+		require.JSONEq(t, `{"title": "audited", "tags": ["a"]}`, resp.Entries[0].Changes)
+		require.Equal(t, todo.EventArchived, resp.Entries[1].Action)
+		require.Equal(t, todo.EventDeleted, resp.Entries[2].Action)
+		require.Contains(t, resp.Entries[2].Changes, "deleted_at")
+
+		other := auth.WithIdentity(ctx, &auth.Identity{Subject: "other"})
+
+		otherResp, err := s.ListAuditEntries(other, &pb.ListAuditEntriesRequest{TaskId: id})
+		require.NoError(t, err)
+		require.Empty(t, otherResp.Entries)
+	})
+
 	t.Run("get task", func(t *testing.T) {
 		resp, err := client.GetTask(
 			ctx,
@@ -112,53 +576,108 @@ func TestServer(t *testing.T) {
 		require.Equal(t, "testing", resp.Task.Title)
 		require.Equal(t, uint64(1), resp.Task.Id)
 	})
-}
 
-func BenchmarkServer(b *testing.B) {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	t.Run("subtasks", func(t *testing.T) {
+		resp, err := client.CreateTask(ctx, &pb.CreateTaskRequest{Title: "subtask", ParentId: 1})
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), resp.Task.ParentId)
 
-	cleanup := func() {
-		walk := func(path string, info fs.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-			if !strings.Contains(path, "testing.db") {
-				return nil
-			}
+		list, err := client.ListTasks(ctx, &pb.ListTasksRequest{ParentId: 1})
+		require.NoError(t, err)
+		require.Len(t, list.Tasks, 1)
+		require.Equal(t, resp.Task.Id, list.Tasks[0].Id)
+		require.Equal(t, uint64(1), list.Tasks[0].ParentId)
+
+		_, err = client.CreateTask(ctx, &pb.CreateTaskRequest{Title: "orphan", ParentId: 999})
+		requireCode(t, twirp.InvalidArgument, err)
+	})
+
+	t.Run("owner", func(t *testing.T) {
+		other := auth.WithIdentity(ctx, &auth.Identity{Subject: "other"})
+
+		list, err := s.ListTasks(other, &pb.ListTasksRequest{})
+		require.NoError(t, err)
+		require.Empty(t, list.Tasks)
+
+		// another owner's task does not exist
+		_, err = s.GetTask(other, &pb.GetTaskRequest{Id: 1})
+		requireCode(t, twirp.NotFound, err)
+
+		_, err = s.DeleteTask(other, &pb.DeleteTaskRequest{Id: 1})
+		requireCode(t, twirp.NotFound, err)
+
+		_, err = s.CreateTask(other, &pb.CreateTaskRequest{Title: "other", ParentId: 1})
+		requireCode(t, twirp.InvalidArgument, err)
+
+		created, err := s.CreateTask(other, &pb.CreateTaskRequest{Title: "other"})
+		require.NoError(t, err)
+
+		_, err = client.GetTask(ctx, &pb.GetTaskRequest{Id: created.Task.Id})
+		requireCode(t, twirp.NotFound, err)
+
+		batch, err := s.BatchGetTasks(other, &pb.BatchGetTasksRequest{Ids: []uint64{1, created.Task.Id}})
+		require.NoError(t, err)
+		require.Len(t, batch.Tasks, 1)
+		require.Equal(t, []uint64{1}, batch.Missing)
+	})
+
+	t.Run("span attributes", func(t *testing.T) {
+		recorder := tracetest.NewSpanRecorder()
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
 
-			err = os.Remove(path)
-			assert.NoError(b, err)
+		spanCtx, span := tp.Tracer("test").Start(ctx, "GetTask")
+		_, err := s.GetTask(spanCtx, &pb.GetTaskRequest{Id: 1})
+		require.NoError(t, err)
+		span.End()
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		require.Contains(t, spans[0].Attributes(), attribute.Int64("task.id", 1))
+	})
 
-			return nil
+	t.Run("idempotency key", func(t *testing.T) {
+		req := pb.CreateTaskRequest{
+			Title:          "idempotent",
+			Tags:           []string{"retry"},
+			IdempotencyKey: "create-once",
 		}
-		err := filepath.Walk("data", walk)
-		assert.NoError(b, err)
-	}
 
-	b.Cleanup(cleanup)
+		first, err := client.CreateTask(ctx, &req)
+		require.NoError(t, err)
 
-	cwd, err := os.Getwd()
-	require.NoError(b, err)
+		second, err := client.CreateTask(ctx, &req)
+		require.NoError(t, err)
+		require.Equal(t, first.Task.Id, second.Task.Id)
+		require.Equal(t, []string{"retry"}, second.Task.Tags)
 
-	dbfile := filepath.Join("data", "testing.db")
+		list, err := client.ListTasks(ctx, &pb.ListTasksRequest{Tag: "retry"})
+		require.NoError(t, err)
+		require.Len(t, list.Tasks, 1)
 
-	cfg := database.Config{
-		SchemaDirectory: filepath.Join(filepath.Dir(filepath.Dir(cwd)), "schema"),
-		Filename:        dbfile,
-	}
+		// keys are scoped to the owner
+		other := auth.WithIdentity(ctx, &auth.Identity{Subject: "other"})
 
-	db, err := cfg.Build(ctx)
-	require.NoError(b, err)
+		third, err := s.CreateTask(other, &req)
+		require.NoError(t, err)
+		require.NotEqual(t, first.Task.Id, third.Task.Id)
+	})
+}
 
-	b.Cleanup(func() { _ = db.Close() })
+func requireCode(t *testing.T, code twirp.ErrorCode, err error) {
+	t.Helper()
 
-	s, err := todo.New(db)
-	require.NoError(b, err)
+	var twerr twirp.Error
+	require.True(t, errors.As(err, &twerr))
+	require.Equal(t, code, twerr.Code())
+}
 
-	b.Cleanup(func() { s.Close() })
+func BenchmarkServer(b *testing.B) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-	_, err = s.CreateTask(
+	s := todotest.New(b).Server
+
+	_, err := s.CreateTask(
 		ctx,
 		&pb.CreateTaskRequest{
 			Title: "testing",
@@ -183,3 +702,276 @@ func BenchmarkServer(b *testing.B) {
 		}
 	})
 }
+
+func TestHooks(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	var events []todo.Event
+
+	s := todotest.New(t,
+		todo.WithHook(func(ctx context.Context, e todo.Event) {
+			panic("hooks may panic")
+		}),
+		todo.WithHook(func(ctx context.Context, e todo.Event) {
+			events = append(events, e)
+		}),
+	).Server
+
+	resp, err := s.CreateTask(ctx, &pb.CreateTaskRequest{Title: "hooked"})
+	require.NoError(t, err)
+
+	_, err = s.DeleteTask(ctx, &pb.DeleteTaskRequest{Id: resp.Task.Id})
+	require.NoError(t, err)
+
+	// failed changes are not reported
+	_, err = s.DeleteTask(ctx, &pb.DeleteTaskRequest{Id: resp.Task.Id})
+	require.Error(t, err)
+
+	// nor are replays of a create with the same idempotency key
+	for i := 0; i < 2; i++ {
+		_, err = s.CreateTask(ctx, &pb.CreateTaskRequest{Title: "replayed", IdempotencyKey: "replayed"})
+		require.NoError(t, err)
+	}
+
+	require.Len(t, events, 3)
+	require.Equal(t, todo.EventCreated, events[0].Type)
+	require.Equal(t, todo.DefaultOwner, events[0].Owner)
+	require.Equal(t, "hooked", events[0].Task.Title)
+	require.Equal(t, todo.EventDeleted, events[1].Type)
+	require.Equal(t, resp.Task.Id, events[1].Task.Id)
+	require.Equal(t, todo.EventCreated, events[2].Type)
+	require.Equal(t, "replayed", events[2].Task.Title)
+}
+
+// serveWatch serves s.WatchHandler as the application does, requiring the
+// bearer token "secret" for the default owner. It returns the base URL and the
+// running server.
+func serveWatch(t *testing.T, s *todo.Server) (string, *httpserver.Server) {
+	t.Helper()
+
+	svr, err := httpserver.New(httpserver.WithShutdownTimeout(time.Second * 5))
+	require.NoError(t, err)
+
+	svr.HandleStream("/watch", auth.Require(auth.StaticToken("secret", todo.DefaultOwner))(s.WatchHandler()))
+	svr.RegisterOnShutdown(s.CloseWatches)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go func() {
+		_ = svr.Run(ctx)
+	}()
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, time.Second*10)
+	defer waitCancel()
+
+	addr, err := svr.WaitForAddress(waitCtx)
+	require.NoError(t, err)
+
+	return "http://" + addr.String(), svr
+}
+
+func TestWatchShutdown(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	s := todotest.New(t).Server
+
+	watch, svr := serveWatch(t, s)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, watch+"/watch", nil)
+	require.NoError(t, err)
+
+	req.Header.Set("Authorization", "Bearer secret")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// an open stream does not hold up a graceful shutdown
+	require.NoError(t, svr.Shutdown(ctx))
+
+	_, err = io.Copy(io.Discard, resp.Body)
+	require.NoError(t, err)
+}
+
+func TestImportRejected(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	db, err := database.Config{SchemaFS: schema.FS, Filename: database.Memory}.Build(ctx)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	// the database rejects a line that passes validation
+	_, err = db.ExecContext(ctx, `create trigger reject_task before insert on tasks when new.title = 'rejected'
+		begin select raise(abort, 'rejected'); end`)
+	require.NoError(t, err)
+
+	s, err := todo.New(db)
+	require.NoError(t, err)
+
+	defer s.Close()
+
+	body := strings.Join([]string{
+		`{"id": 1, "title": "before"}`,
+		`{"id": 2, "title": "rejected"}`,
+		`{"id": 3, "title": "after", "parent_id": 1}`,
+	}, "\n")
+
+	adminCtx := auth.WithIdentity(ctx, &auth.Identity{Subject: "admin", Admin: true})
+
+	req := httptest.NewRequest(http.MethodPost, "/import", strings.NewReader(body))
+	req = req.WithContext(adminCtx)
+
+	rec := httptest.NewRecorder()
+	s.ImportHandler().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var result todo.ImportResult
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+
+	// only the rejected line fails, and its neighbors are inserted
+	require.Equal(t, 2, result.Inserted)
+	require.Equal(t, 1, result.Failed)
+	require.Len(t, result.Errors, 1)
+	require.Equal(t, 2, result.Errors[0].Line)
+	require.Equal(t, "constraint violation", result.Errors[0].Error)
+
+	list, err := s.ListTasks(adminCtx, &pb.ListTasksRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.Tasks, 2)
+	require.Equal(t, "before", list.Tasks[0].Title)
+	require.Equal(t, "after", list.Tasks[1].Title)
+	require.Equal(t, list.Tasks[0].Id, list.Tasks[1].ParentId)
+}
+
+func TestReadOnly(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	cfg := database.Config{
+		SchemaFS: schema.FS,
+		Filename: filepath.Join(t.TempDir(), "testing.db"),
+	}
+
+	db, err := cfg.Build(ctx)
+	require.NoError(t, err)
+
+	s, err := todo.New(db)
+	require.NoError(t, err)
+
+	created, err := s.CreateTask(ctx, &pb.CreateTaskRequest{Title: "testing"})
+	require.NoError(t, err)
+
+	s.Close()
+	require.NoError(t, db.Close())
+
+	cfg.ReadOnly = true
+
+	ro, err := cfg.Build(ctx)
+	require.NoError(t, err)
+
+	defer ro.Close()
+
+	s, err = todo.New(ro)
+	require.NoError(t, err)
+
+	defer s.Close()
+
+	_, err = s.GetTask(ctx, &pb.GetTaskRequest{Id: created.Task.Id})
+	require.NoError(t, err)
+
+	_, err = s.CreateTask(ctx, &pb.CreateTaskRequest{Title: "testing"})
+	requireCode(t, twirp.FailedPrecondition, err)
+
+	var twerr twirp.Error
+	require.True(t, errors.As(err, &twerr))
+	require.Equal(t, "database is read-only", twerr.Msg())
+
+	_, err = s.DeleteTask(ctx, &pb.DeleteTaskRequest{Id: created.Task.Id})
+	requireCode(t, twirp.FailedPrecondition, err)
+}
+
+func TestHarnessInterceptors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	h := todotest.NewWithAuth(t, auth.StaticToken("secret", "tester"))
+
+	_, err := h.Client.CreateTask(ctx, &pb.CreateTaskRequest{Title: "testing"})
+	require.Error(t, err)
+
+	var twerr twirp.Error
+	require.True(t, errors.As(err, &twerr))
+	require.Equal(t, twirp.Unauthenticated, twerr.Code())
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer secret")
+
+	ctx, err = twirp.WithHTTPRequestHeaders(ctx, header)
+	require.NoError(t, err)
+
+	resp, err := h.Client.CreateTask(ctx, &pb.CreateTaskRequest{Title: "testing"})
+	require.NoError(t, err)
+	require.Equal(t, "testing", resp.Task.Title)
+
+	_, err = h.Client.CreateTask(ctx, &pb.CreateTaskRequest{})
+	require.Error(t, err)
+	require.True(t, errors.As(err, &twerr))
+	require.Equal(t, twirp.InvalidArgument, twerr.Code())
+	require.Equal(t, "title", twerr.Meta("argument"))
+}
+
+func TestTablePrefix(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	cfg := database.Config{
+		SchemaFS:    schema.FS,
+		Filename:    database.Memory,
+		TablePrefix: "tenant_",
+	}
+
+	db, err := cfg.Build(ctx)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	s, err := todo.New(db, todo.WithTablePrefix(cfg.TablePrefix))
+	require.NoError(t, err)
+
+	defer s.Close()
+
+	parent, err := s.CreateTask(ctx, &pb.CreateTaskRequest{Title: "parent", Tags: []string{"a"}})
+	require.NoError(t, err)
+
+	child, err := s.CreateTask(ctx, &pb.CreateTaskRequest{Title: "child", ParentId: parent.Task.Id})
+	require.NoError(t, err)
+
+	_, err = s.ReorderTask(ctx, &pb.ReorderTaskRequest{Id: child.Task.Id, Position: 1})
+	require.NoError(t, err)
+
+	_, err = s.ArchiveTask(ctx, &pb.ArchiveTaskRequest{Id: parent.Task.Id})
+	require.NoError(t, err)
+
+	list, err := s.ListTasks(ctx, &pb.ListTasksRequest{Tag: "a", IncludeArchived: true})
+	require.NoError(t, err)
+	require.Len(t, list.Tasks, 1)
+	require.Equal(t, parent.Task.Id, list.Tasks[0].Id)
+
+	audit, err := s.ListAuditEntries(ctx, &pb.ListAuditEntriesRequest{TaskId: parent.Task.Id})
+	require.NoError(t, err)
+	require.Len(t, audit.Entries, 2)
+
+	// every row is in the prefixed tables
+	var count int
+	require.NoError(t, db.QueryRowContext(ctx, "select count(*) from tenant_tasks").Scan(&count))
+	require.Equal(t, 2, count)
+}
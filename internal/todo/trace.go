@@ -0,0 +1,24 @@
+package todo
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	taskIDKey       = attribute.Key("task.id")
+	tasksCountKey   = attribute.Key("tasks.count")
+	tasksMissingKey = attribute.Key("tasks.missing")
+)
+
+// setAttributes adds attributes to the span in ctx if it is recording.
+func setAttributes(ctx context.Context, attrs ...attribute.KeyValue) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	span.SetAttributes(attrs...)
+}
@@ -0,0 +1,72 @@
+package todo
+
+import (
+	"unicode/utf8"
+
+	"github.com/bakins/twirp-todo-example/internal/validate"
+	pb "github.com/bakins/twirp-todo-example/proto"
+)
+
+const (
+	maxTitleLength       = 256
+	maxDescriptionLength = 4096
+	maxTags              = 32
+	maxTagLength         = 64
+	maxIdempotencyKey    = 128
+)
+
+// Validate checks the fields of TodoService requests. It is intended for
+// validate.WithFunc, and returns nil for requests it does not know.
+func Validate(req interface{}) error {
+	switch req := req.(type) {
+	case *pb.CreateTaskRequest:
+		return validateCreateTask(req)
+	case *pb.DeleteTaskRequest:
+		return requireID("id", req.GetId())
+	case *pb.RestoreTaskRequest:
+		return requireID("id", req.GetId())
+	case *pb.GetTaskRequest:
+		return requireID("id", req.GetId())
+	case *pb.ArchiveTaskRequest:
+		return requireID("id", req.GetId())
+	case *pb.UnarchiveTaskRequest:
+		return requireID("id", req.GetId())
+	case *pb.ReorderTaskRequest:
+		return requireID("id", req.GetId())
+	case *pb.ListAuditEntriesRequest:
+		return requireID("task_id", req.GetTaskId())
+	default:
+		return nil
+	}
+}
+
+func validateCreateTask(req *pb.CreateTaskRequest) error {
+	switch {
+	case req.GetTitle() == "":
+		return validate.Errorf("title", "is required")
+	case utf8.RuneCountInString(req.GetTitle()) > maxTitleLength:
+		return validate.Errorf("title", "must be at most %d characters", maxTitleLength)
+	case utf8.RuneCountInString(req.GetDescription()) > maxDescriptionLength:
+		return validate.Errorf("description", "must be at most %d characters", maxDescriptionLength)
+	case len(req.GetTags()) > maxTags:
+		return validate.Errorf("tags", "must have at most %d entries", maxTags)
+	case len(req.GetIdempotencyKey()) > maxIdempotencyKey:
+		return validate.Errorf("idempotency_key", "must be at most %d bytes", maxIdempotencyKey)
+	}
+
+	for _, tag := range req.GetTags() {
+		if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+			return validate.Errorf("tags", "must be between 1 and %d characters", maxTagLength)
+		}
+	}
+
+	return nil
+}
+
+func requireID(field string, id uint64) error {
+	if id == 0 {
+		return validate.Errorf(field, "is required")
+	}
+
+	return nil
+}
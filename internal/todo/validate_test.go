@@ -0,0 +1,44 @@
+package todo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/twirp-todo-example/internal/todo"
+	"github.com/bakins/twirp-todo-example/internal/validate"
+	pb "github.com/bakins/twirp-todo-example/proto"
+)
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, todo.Validate(&pb.CreateTaskRequest{Title: "testing", Tags: []string{"a"}}))
+	require.NoError(t, todo.Validate(&pb.GetTaskRequest{Id: 1}))
+
+	// requests without rules are not checked
+	require.NoError(t, todo.Validate(&pb.ListTasksRequest{}))
+
+	tests := map[string]struct {
+		req   interface{}
+		field string
+	}{
+		"no title":     {req: &pb.CreateTaskRequest{}, field: "title"},
+		"long title":   {req: &pb.CreateTaskRequest{Title: strings.Repeat("a", 257)}, field: "title"},
+		"empty tag":    {req: &pb.CreateTaskRequest{Title: "testing", Tags: []string{""}}, field: "tags"},
+		"no id":        {req: &pb.DeleteTaskRequest{}, field: "id"},
+		"no task id":   {req: &pb.ListAuditEntriesRequest{}, field: "task_id"},
+		"no reorder":   {req: &pb.ReorderTaskRequest{Position: 1}, field: "id"},
+		"no unarchive": {req: &pb.UnarchiveTaskRequest{}, field: "id"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := todo.Validate(test.req)
+			require.Error(t, err)
+
+			var fe *validate.FieldError
+			require.ErrorAs(t, err, &fe)
+			require.Equal(t, test.field, fe.Field())
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package todo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	// watchBuffer is the number of events queued for each watcher. Watchers
+	// that fall further behind are disconnected.
+	watchBuffer = 64
+	// watchKeepAlive is how often a comment is sent to idle watchers, so
+	// proxies do not close the connection.
+	watchKeepAlive = 15 * time.Second
+)
+
+type subscriber struct {
+	owner  string
+	events chan Event
+}
+
+// broker sends events to subscribers in the same process.
+type broker struct {
+	lock        sync.Mutex
+	subscribers map[*subscriber]struct{}
+	closed      bool
+}
+
+func newBroker() *broker {
+	return &broker{
+		subscribers: map[*subscriber]struct{}{},
+	}
+}
+
+// subscribe returns nil once the broker is closed.
+func (b *broker) subscribe(owner string) *subscriber {
+	sub := subscriber{
+		owner:  owner,
+		events: make(chan Event, watchBuffer),
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.closed {
+		return nil
+	}
+
+	b.subscribers[&sub] = struct{}{}
+
+	return &sub
+}
+
+// unsubscribe closes the subscriber's events if it has not already been
+// dropped.
+func (b *broker) unsubscribe(sub *subscriber) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// close ends every subscription and refuses new ones.
+func (b *broker) close() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.closed = true
+
+	for sub := range b.subscribers {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// publish never blocks. Subscribers with a full buffer are dropped.
+func (b *broker) publish(ctx context.Context, e Event) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for sub := range b.subscribers {
+		if sub.owner != e.Owner {
+			continue
+		}
+
+		select {
+		case sub.events <- e:
+		default:
+			delete(b.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// WatchHandler streams changes to the caller's tasks as server-sent events.
+// The event name is the type of change, such as created, and the data is the
+// task as JSON. The stream ends if the client falls too far behind, and
+// clients should reconnect and reread their tasks.
+//
+// Only changes made by this process are seen. A write timeout on the HTTP
+// server also limits how long a stream may last, so it should be disabled.
+//
+// The caller's identity is read from the request context, so the handler
+// should be wrapped with auth.Require when authentication is enabled.
+// Streams end when CloseWatches or Close is called.
+func (s *Server) WatchHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+
+		ctx := r.Context()
+
+		sub := s.broker.subscribe(owner(ctx))
+		if sub == nil {
+			http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+			return
+		}
+
+		defer s.broker.unsubscribe(sub)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		keepAlive := time.NewTicker(watchKeepAlive)
+		defer keepAlive.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-keepAlive.C:
+				if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+					return
+				}
+			case e, ok := <-sub.events:
+				if !ok {
+					return
+				}
+
+				data, err := protojson.Marshal(e.Task)
+				if err != nil {
+					return
+				}
+
+				if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data); err != nil {
+					return
+				}
+			}
+
+			flusher.Flush()
+		}
+	})
+}
+
+// CloseWatches ends every stream served by WatchHandler and refuses new ones.
+// Streams otherwise only end when the client disconnects, so this should be
+// called when the HTTP server begins a graceful shutdown.
+func (s *Server) CloseWatches() {
+	s.broker.close()
+}
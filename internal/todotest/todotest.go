@@ -0,0 +1,107 @@
+// Package todotest provides a todo server backed by an in-memory database for
+// use in tests.
+package todotest
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/bakins/twirp-todo-example/internal/auth"
+	"github.com/bakins/twirp-todo-example/internal/database"
+	"github.com/bakins/twirp-todo-example/internal/service"
+	"github.com/bakins/twirp-todo-example/internal/todo"
+	pb "github.com/bakins/twirp-todo-example/proto"
+	"github.com/bakins/twirp-todo-example/schema"
+)
+
+// Harness is a todo server listening on a local address.
+type Harness struct {
+	// DB is the in-memory database, migrated with the embedded schema.
+	DB *sql.DB
+	// Server may be called directly, such as with a context holding an
+	// identity, or used for its HTTP handlers.
+	Server *todo.Server
+	// HTTP serves the twirp service with the interceptors used by the
+	// application.
+	HTTP *httptest.Server
+	// Client is connected to HTTP using protobuf.
+	Client pb.TodoService
+
+	closeOnce sync.Once
+}
+
+// New creates a harness without authentication. Options are passed to
+// todo.New. Close is called when the test finishes.
+func New(t testing.TB, options ...todo.Option) *Harness {
+	t.Helper()
+
+	return newHarness(t, nil, options)
+}
+
+// NewWithAuth creates a harness that requires callers to be authenticated by
+// verifier. Options are passed to todo.New. Close is called when the test
+// finishes.
+func NewWithAuth(t testing.TB, verifier auth.Verifier, options ...todo.Option) *Harness {
+	t.Helper()
+
+	return newHarness(t, verifier, options)
+}
+
+func newHarness(t testing.TB, verifier auth.Verifier, options []todo.Option) *Harness {
+	t.Helper()
+
+	cfg := database.Config{
+		SchemaFS: schema.FS,
+		Filename: database.Memory,
+	}
+
+	db, err := cfg.Build(context.Background())
+	if err != nil {
+		t.Fatalf("failed to create database %v", err)
+	}
+
+	s, err := todo.New(db, options...)
+	if err != nil {
+		_ = db.Close()
+		t.Fatalf("failed to create server %v", err)
+	}
+
+	var serviceOptions []service.Option
+	if verifier != nil {
+		serviceOptions = append(serviceOptions, service.WithAuth(verifier))
+	}
+
+	ts, err := service.New(s, serviceOptions...)
+	if err != nil {
+		s.Close()
+		_ = db.Close()
+		t.Fatalf("failed to create service %v", err)
+	}
+
+	svr := httptest.NewServer(auth.Middleware(ts))
+
+	h := Harness{
+		DB:     db,
+		Server: s,
+		HTTP:   svr,
+		Client: pb.NewTodoServiceProtobufClient(svr.URL, http.DefaultClient),
+	}
+
+	t.Cleanup(h.Close)
+
+	return &h
+}
+
+// Close stops the HTTP server and closes the todo server and database. It
+// may be called more than once.
+func (h *Harness) Close() {
+	h.closeOnce.Do(func() {
+		h.HTTP.Close()
+		h.Server.Close()
+		_ = h.DB.Close()
+	})
+}
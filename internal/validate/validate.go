@@ -0,0 +1,115 @@
+// Package validate provides request validation for Twirp services.
+package validate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/twitchtv/twirp"
+)
+
+// Validator is implemented by request messages that can check themselves,
+// such as those generated by protoc-gen-validate.
+type Validator interface {
+	Validate() error
+}
+
+// fieldError matches the errors returned by protoc-gen-validate as well as
+// FieldError.
+type fieldError interface {
+	Field() string
+	Reason() string
+}
+
+// FieldError describes an invalid field.
+type FieldError struct {
+	field  string
+	reason string
+}
+
+// Errorf creates a FieldError for field.
+func Errorf(field string, format string, args ...interface{}) *FieldError {
+	return &FieldError{
+		field:  field,
+		reason: fmt.Sprintf(format, args...),
+	}
+}
+
+func (e *FieldError) Field() string {
+	return e.field
+}
+
+func (e *FieldError) Reason() string {
+	return e.reason
+}
+
+func (e *FieldError) Error() string {
+	return e.field + " " + e.reason
+}
+
+// Func validates requests that do not implement Validator, such as generated
+// messages whose rules are kept outside the generated package. It should
+// return nil for requests it does not know.
+type Func func(req interface{}) error
+
+type interceptorConfig struct {
+	funcs []Func
+}
+
+type Option interface {
+	apply(*interceptorConfig)
+}
+
+type interceptorOptionFunc func(*interceptorConfig)
+
+func (f interceptorOptionFunc) apply(c *interceptorConfig) {
+	f(c)
+}
+
+// WithFunc adds a validation function, called for every request after
+// Validate.
+func WithFunc(f Func) Option {
+	return interceptorOptionFunc(func(c *interceptorConfig) {
+		c.funcs = append(c.funcs, f)
+	})
+}
+
+// Interceptor returns a twirp interceptor that validates requests that
+// implement Validator, or are checked by a function added with WithFunc,
+// before calling the handler. Invalid requests are rejected with an
+// invalid_argument error. Other requests are passed through unchanged.
+func Interceptor(options ...Option) twirp.Interceptor {
+	var cfg interceptorConfig
+
+	for _, o := range options {
+		o.apply(&cfg)
+	}
+
+	return func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if v, ok := req.(Validator); ok {
+				if err := v.Validate(); err != nil {
+					return nil, invalidArgument(err)
+				}
+			}
+
+			for _, f := range cfg.funcs {
+				if err := f(req); err != nil {
+					return nil, invalidArgument(err)
+				}
+			}
+
+			return next(ctx, req)
+		}
+	}
+}
+
+func invalidArgument(err error) error {
+	var fe fieldError
+	if errors.As(err, &fe) {
+		return twirp.InvalidArgumentError(fe.Field(), fe.Reason())
+	}
+
+	return twirp.NewError(twirp.InvalidArgument, err.Error())
+}
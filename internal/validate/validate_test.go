@@ -0,0 +1,75 @@
+package validate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+
+	"github.com/bakins/twirp-todo-example/internal/validate"
+)
+
+type request struct {
+	name string
+}
+
+func (r *request) Validate() error {
+	if r.name == "" {
+		return validate.Errorf("name", "is required")
+	}
+
+	return nil
+}
+
+type other struct {
+	id int
+}
+
+func TestInterceptor(t *testing.T) {
+	validateOther := func(req interface{}) error {
+		if o, ok := req.(*other); ok && o.id == 0 {
+			return validate.Errorf("id", "is required")
+		}
+
+		return nil
+	}
+
+	method := validate.Interceptor(validate.WithFunc(validateOther))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+
+	resp, err := method(context.Background(), &request{name: "testing"})
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp)
+
+	resp, err = method(context.Background(), &other{id: 1})
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp)
+
+	// requests that are not validated are passed through
+	resp, err = method(context.Background(), "unknown")
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp)
+
+	tests := map[string]struct {
+		req      interface{}
+		argument string
+	}{
+		"Validate": {req: &request{}, argument: "name"},
+		"WithFunc": {req: &other{}, argument: "id"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := method(context.Background(), test.req)
+			require.Error(t, err)
+
+			var twerr twirp.Error
+			require.True(t, errors.As(err, &twerr))
+			require.Equal(t, twirp.InvalidArgument, twerr.Code())
+			require.Equal(t, test.argument, twerr.Meta("argument"))
+		})
+	}
+}
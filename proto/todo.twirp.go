@@ -0,0 +1,3659 @@
+// Code generated by protoc-gen-twirp v8.1.2, DO NOT EDIT.
+// source: proto/todo.proto
+
+package proto
+
+import context "context"
+import fmt "fmt"
+import http "net/http"
+import ioutil "io/ioutil"
+import json "encoding/json"
+import strconv "strconv"
+import strings "strings"
+
+import protojson "google.golang.org/protobuf/encoding/protojson"
+import proto "google.golang.org/protobuf/proto"
+import twirp "github.com/twitchtv/twirp"
+import ctxsetters "github.com/twitchtv/twirp/ctxsetters"
+
+import bytes "bytes"
+import errors "errors"
+import io "io"
+import path "path"
+import url "net/url"
+
+// Version compatibility assertion.
+// If the constant is not defined in the package, that likely means
+// the package needs to be updated to work with this generated code.
+// See https://twitchtv.github.io/twirp/docs/version_matrix.html
+const _ = twirp.TwirpPackageMinVersion_8_1_0
+
+// =====================
+// TodoService Interface
+// =====================
+
+type TodoService interface {
+	ListTasks(context.Context, *ListTasksRequest) (*ListTasksResponse, error)
+
+	CreateTask(context.Context, *CreateTaskRequest) (*CreateTaskResponse, error)
+
+	GetTask(context.Context, *GetTaskRequest) (*GetTaskResponse, error)
+
+	BatchGetTasks(context.Context, *BatchGetTasksRequest) (*BatchGetTasksResponse, error)
+
+	DeleteTask(context.Context, *DeleteTaskRequest) (*DeleteTaskResponse, error)
+
+	RestoreTask(context.Context, *RestoreTaskRequest) (*RestoreTaskResponse, error)
+
+	ArchiveTask(context.Context, *ArchiveTaskRequest) (*ArchiveTaskResponse, error)
+
+	UnarchiveTask(context.Context, *UnarchiveTaskRequest) (*UnarchiveTaskResponse, error)
+
+	ReorderTask(context.Context, *ReorderTaskRequest) (*ReorderTaskResponse, error)
+
+	ListAuditEntries(context.Context, *ListAuditEntriesRequest) (*ListAuditEntriesResponse, error)
+}
+
+// ===========================
+// TodoService Protobuf Client
+// ===========================
+
+type todoServiceProtobufClient struct {
+	client      HTTPClient
+	urls        [10]string
+	interceptor twirp.Interceptor
+	opts        twirp.ClientOptions
+}
+
+// NewTodoServiceProtobufClient creates a Protobuf client that implements the TodoService interface.
+// It communicates using Protobuf and can be configured with a custom HTTPClient.
+func NewTodoServiceProtobufClient(baseURL string, client HTTPClient, opts ...twirp.ClientOption) TodoService {
+	if c, ok := client.(*http.Client); ok {
+		client = withoutRedirects(c)
+	}
+
+	clientOpts := twirp.ClientOptions{}
+	for _, o := range opts {
+		o(&clientOpts)
+	}
+
+	// Using ReadOpt allows backwards and forwads compatibility with new options in the future
+	literalURLs := false
+	_ = clientOpts.ReadOpt("literalURLs", &literalURLs)
+	var pathPrefix string
+	if ok := clientOpts.ReadOpt("pathPrefix", &pathPrefix); !ok {
+		pathPrefix = "/twirp" // default prefix
+	}
+
+	// Build method URLs: <baseURL>[<prefix>]/<package>.<Service>/<Method>
+	serviceURL := sanitizeBaseURL(baseURL)
+	serviceURL += baseServicePath(pathPrefix, "bakins.todo.v1", "TodoService")
+	urls := [10]string{
+		serviceURL + "ListTasks",
+		serviceURL + "CreateTask",
+		serviceURL + "GetTask",
+		serviceURL + "BatchGetTasks",
+		serviceURL + "DeleteTask",
+		serviceURL + "RestoreTask",
+		serviceURL + "ArchiveTask",
+		serviceURL + "UnarchiveTask",
+		serviceURL + "ReorderTask",
+		serviceURL + "ListAuditEntries",
+	}
+
+	return &todoServiceProtobufClient{
+		client:      client,
+		urls:        urls,
+		interceptor: twirp.ChainInterceptors(clientOpts.Interceptors...),
+		opts:        clientOpts,
+	}
+}
+
+func (c *todoServiceProtobufClient) ListTasks(ctx context.Context, in *ListTasksRequest) (*ListTasksResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "bakins.todo.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "TodoService")
+	ctx = ctxsetters.WithMethodName(ctx, "ListTasks")
+	caller := c.callListTasks
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ListTasksRequest) (*ListTasksResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListTasksRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListTasksRequest) when calling interceptor")
+					}
+					return c.callListTasks(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListTasksResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListTasksResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *todoServiceProtobufClient) callListTasks(ctx context.Context, in *ListTasksRequest) (*ListTasksResponse, error) {
+	out := new(ListTasksResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[0], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *todoServiceProtobufClient) CreateTask(ctx context.Context, in *CreateTaskRequest) (*CreateTaskResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "bakins.todo.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "TodoService")
+	ctx = ctxsetters.WithMethodName(ctx, "CreateTask")
+	caller := c.callCreateTask
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *CreateTaskRequest) (*CreateTaskResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*CreateTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*CreateTaskRequest) when calling interceptor")
+					}
+					return c.callCreateTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*CreateTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*CreateTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *todoServiceProtobufClient) callCreateTask(ctx context.Context, in *CreateTaskRequest) (*CreateTaskResponse, error) {
+	out := new(CreateTaskResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[1], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *todoServiceProtobufClient) GetTask(ctx context.Context, in *GetTaskRequest) (*GetTaskResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "bakins.todo.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "TodoService")
+	ctx = ctxsetters.WithMethodName(ctx, "GetTask")
+	caller := c.callGetTask
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *GetTaskRequest) (*GetTaskResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GetTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GetTaskRequest) when calling interceptor")
+					}
+					return c.callGetTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GetTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GetTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *todoServiceProtobufClient) callGetTask(ctx context.Context, in *GetTaskRequest) (*GetTaskResponse, error) {
+	out := new(GetTaskResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[2], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *todoServiceProtobufClient) BatchGetTasks(ctx context.Context, in *BatchGetTasksRequest) (*BatchGetTasksResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "bakins.todo.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "TodoService")
+	ctx = ctxsetters.WithMethodName(ctx, "BatchGetTasks")
+	caller := c.callBatchGetTasks
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *BatchGetTasksRequest) (*BatchGetTasksResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*BatchGetTasksRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*BatchGetTasksRequest) when calling interceptor")
+					}
+					return c.callBatchGetTasks(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*BatchGetTasksResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*BatchGetTasksResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *todoServiceProtobufClient) callBatchGetTasks(ctx context.Context, in *BatchGetTasksRequest) (*BatchGetTasksResponse, error) {
+	out := new(BatchGetTasksResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[3], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *todoServiceProtobufClient) DeleteTask(ctx context.Context, in *DeleteTaskRequest) (*DeleteTaskResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "bakins.todo.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "TodoService")
+	ctx = ctxsetters.WithMethodName(ctx, "DeleteTask")
+	caller := c.callDeleteTask
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *DeleteTaskRequest) (*DeleteTaskResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DeleteTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DeleteTaskRequest) when calling interceptor")
+					}
+					return c.callDeleteTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DeleteTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DeleteTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *todoServiceProtobufClient) callDeleteTask(ctx context.Context, in *DeleteTaskRequest) (*DeleteTaskResponse, error) {
+	out := new(DeleteTaskResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[4], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *todoServiceProtobufClient) RestoreTask(ctx context.Context, in *RestoreTaskRequest) (*RestoreTaskResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "bakins.todo.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "TodoService")
+	ctx = ctxsetters.WithMethodName(ctx, "RestoreTask")
+	caller := c.callRestoreTask
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *RestoreTaskRequest) (*RestoreTaskResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RestoreTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RestoreTaskRequest) when calling interceptor")
+					}
+					return c.callRestoreTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RestoreTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RestoreTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *todoServiceProtobufClient) callRestoreTask(ctx context.Context, in *RestoreTaskRequest) (*RestoreTaskResponse, error) {
+	out := new(RestoreTaskResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[5], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *todoServiceProtobufClient) ArchiveTask(ctx context.Context, in *ArchiveTaskRequest) (*ArchiveTaskResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "bakins.todo.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "TodoService")
+	ctx = ctxsetters.WithMethodName(ctx, "ArchiveTask")
+	caller := c.callArchiveTask
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ArchiveTaskRequest) (*ArchiveTaskResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ArchiveTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ArchiveTaskRequest) when calling interceptor")
+					}
+					return c.callArchiveTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ArchiveTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ArchiveTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *todoServiceProtobufClient) callArchiveTask(ctx context.Context, in *ArchiveTaskRequest) (*ArchiveTaskResponse, error) {
+	out := new(ArchiveTaskResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[6], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *todoServiceProtobufClient) UnarchiveTask(ctx context.Context, in *UnarchiveTaskRequest) (*UnarchiveTaskResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "bakins.todo.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "TodoService")
+	ctx = ctxsetters.WithMethodName(ctx, "UnarchiveTask")
+	caller := c.callUnarchiveTask
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *UnarchiveTaskRequest) (*UnarchiveTaskResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*UnarchiveTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*UnarchiveTaskRequest) when calling interceptor")
+					}
+					return c.callUnarchiveTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*UnarchiveTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*UnarchiveTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *todoServiceProtobufClient) callUnarchiveTask(ctx context.Context, in *UnarchiveTaskRequest) (*UnarchiveTaskResponse, error) {
+	out := new(UnarchiveTaskResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[7], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *todoServiceProtobufClient) ReorderTask(ctx context.Context, in *ReorderTaskRequest) (*ReorderTaskResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "bakins.todo.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "TodoService")
+	ctx = ctxsetters.WithMethodName(ctx, "ReorderTask")
+	caller := c.callReorderTask
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ReorderTaskRequest) (*ReorderTaskResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ReorderTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ReorderTaskRequest) when calling interceptor")
+					}
+					return c.callReorderTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ReorderTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ReorderTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *todoServiceProtobufClient) callReorderTask(ctx context.Context, in *ReorderTaskRequest) (*ReorderTaskResponse, error) {
+	out := new(ReorderTaskResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[8], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *todoServiceProtobufClient) ListAuditEntries(ctx context.Context, in *ListAuditEntriesRequest) (*ListAuditEntriesResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "bakins.todo.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "TodoService")
+	ctx = ctxsetters.WithMethodName(ctx, "ListAuditEntries")
+	caller := c.callListAuditEntries
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ListAuditEntriesRequest) (*ListAuditEntriesResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListAuditEntriesRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListAuditEntriesRequest) when calling interceptor")
+					}
+					return c.callListAuditEntries(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListAuditEntriesResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListAuditEntriesResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *todoServiceProtobufClient) callListAuditEntries(ctx context.Context, in *ListAuditEntriesRequest) (*ListAuditEntriesResponse, error) {
+	out := new(ListAuditEntriesResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[9], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+// =======================
+// TodoService JSON Client
+// =======================
+
+type todoServiceJSONClient struct {
+	client      HTTPClient
+	urls        [10]string
+	interceptor twirp.Interceptor
+	opts        twirp.ClientOptions
+}
+
+// NewTodoServiceJSONClient creates a JSON client that implements the TodoService interface.
+// It communicates using JSON and can be configured with a custom HTTPClient.
+func NewTodoServiceJSONClient(baseURL string, client HTTPClient, opts ...twirp.ClientOption) TodoService {
+	if c, ok := client.(*http.Client); ok {
+		client = withoutRedirects(c)
+	}
+
+	clientOpts := twirp.ClientOptions{}
+	for _, o := range opts {
+		o(&clientOpts)
+	}
+
+	// Using ReadOpt allows backwards and forwads compatibility with new options in the future
+	literalURLs := false
+	_ = clientOpts.ReadOpt("literalURLs", &literalURLs)
+	var pathPrefix string
+	if ok := clientOpts.ReadOpt("pathPrefix", &pathPrefix); !ok {
+		pathPrefix = "/twirp" // default prefix
+	}
+
+	// Build method URLs: <baseURL>[<prefix>]/<package>.<Service>/<Method>
+	serviceURL := sanitizeBaseURL(baseURL)
+	serviceURL += baseServicePath(pathPrefix, "bakins.todo.v1", "TodoService")
+	urls := [10]string{
+		serviceURL + "ListTasks",
+		serviceURL + "CreateTask",
+		serviceURL + "GetTask",
+		serviceURL + "BatchGetTasks",
+		serviceURL + "DeleteTask",
+		serviceURL + "RestoreTask",
+		serviceURL + "ArchiveTask",
+		serviceURL + "UnarchiveTask",
+		serviceURL + "ReorderTask",
+		serviceURL + "ListAuditEntries",
+	}
+
+	return &todoServiceJSONClient{
+		client:      client,
+		urls:        urls,
+		interceptor: twirp.ChainInterceptors(clientOpts.Interceptors...),
+		opts:        clientOpts,
+	}
+}
+
+func (c *todoServiceJSONClient) ListTasks(ctx context.Context, in *ListTasksRequest) (*ListTasksResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "bakins.todo.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "TodoService")
+	ctx = ctxsetters.WithMethodName(ctx, "ListTasks")
+	caller := c.callListTasks
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ListTasksRequest) (*ListTasksResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListTasksRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListTasksRequest) when calling interceptor")
+					}
+					return c.callListTasks(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListTasksResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListTasksResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *todoServiceJSONClient) callListTasks(ctx context.Context, in *ListTasksRequest) (*ListTasksResponse, error) {
+	out := new(ListTasksResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[0], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *todoServiceJSONClient) CreateTask(ctx context.Context, in *CreateTaskRequest) (*CreateTaskResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "bakins.todo.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "TodoService")
+	ctx = ctxsetters.WithMethodName(ctx, "CreateTask")
+	caller := c.callCreateTask
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *CreateTaskRequest) (*CreateTaskResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*CreateTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*CreateTaskRequest) when calling interceptor")
+					}
+					return c.callCreateTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*CreateTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*CreateTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *todoServiceJSONClient) callCreateTask(ctx context.Context, in *CreateTaskRequest) (*CreateTaskResponse, error) {
+	out := new(CreateTaskResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[1], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *todoServiceJSONClient) GetTask(ctx context.Context, in *GetTaskRequest) (*GetTaskResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "bakins.todo.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "TodoService")
+	ctx = ctxsetters.WithMethodName(ctx, "GetTask")
+	caller := c.callGetTask
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *GetTaskRequest) (*GetTaskResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GetTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GetTaskRequest) when calling interceptor")
+					}
+					return c.callGetTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GetTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GetTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *todoServiceJSONClient) callGetTask(ctx context.Context, in *GetTaskRequest) (*GetTaskResponse, error) {
+	out := new(GetTaskResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[2], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *todoServiceJSONClient) BatchGetTasks(ctx context.Context, in *BatchGetTasksRequest) (*BatchGetTasksResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "bakins.todo.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "TodoService")
+	ctx = ctxsetters.WithMethodName(ctx, "BatchGetTasks")
+	caller := c.callBatchGetTasks
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *BatchGetTasksRequest) (*BatchGetTasksResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*BatchGetTasksRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*BatchGetTasksRequest) when calling interceptor")
+					}
+					return c.callBatchGetTasks(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*BatchGetTasksResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*BatchGetTasksResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *todoServiceJSONClient) callBatchGetTasks(ctx context.Context, in *BatchGetTasksRequest) (*BatchGetTasksResponse, error) {
+	out := new(BatchGetTasksResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[3], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *todoServiceJSONClient) DeleteTask(ctx context.Context, in *DeleteTaskRequest) (*DeleteTaskResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "bakins.todo.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "TodoService")
+	ctx = ctxsetters.WithMethodName(ctx, "DeleteTask")
+	caller := c.callDeleteTask
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *DeleteTaskRequest) (*DeleteTaskResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DeleteTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DeleteTaskRequest) when calling interceptor")
+					}
+					return c.callDeleteTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DeleteTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DeleteTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *todoServiceJSONClient) callDeleteTask(ctx context.Context, in *DeleteTaskRequest) (*DeleteTaskResponse, error) {
+	out := new(DeleteTaskResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[4], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *todoServiceJSONClient) RestoreTask(ctx context.Context, in *RestoreTaskRequest) (*RestoreTaskResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "bakins.todo.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "TodoService")
+	ctx = ctxsetters.WithMethodName(ctx, "RestoreTask")
+	caller := c.callRestoreTask
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *RestoreTaskRequest) (*RestoreTaskResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RestoreTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RestoreTaskRequest) when calling interceptor")
+					}
+					return c.callRestoreTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RestoreTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RestoreTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *todoServiceJSONClient) callRestoreTask(ctx context.Context, in *RestoreTaskRequest) (*RestoreTaskResponse, error) {
+	out := new(RestoreTaskResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[5], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *todoServiceJSONClient) ArchiveTask(ctx context.Context, in *ArchiveTaskRequest) (*ArchiveTaskResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "bakins.todo.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "TodoService")
+	ctx = ctxsetters.WithMethodName(ctx, "ArchiveTask")
+	caller := c.callArchiveTask
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ArchiveTaskRequest) (*ArchiveTaskResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ArchiveTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ArchiveTaskRequest) when calling interceptor")
+					}
+					return c.callArchiveTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ArchiveTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ArchiveTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *todoServiceJSONClient) callArchiveTask(ctx context.Context, in *ArchiveTaskRequest) (*ArchiveTaskResponse, error) {
+	out := new(ArchiveTaskResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[6], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *todoServiceJSONClient) UnarchiveTask(ctx context.Context, in *UnarchiveTaskRequest) (*UnarchiveTaskResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "bakins.todo.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "TodoService")
+	ctx = ctxsetters.WithMethodName(ctx, "UnarchiveTask")
+	caller := c.callUnarchiveTask
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *UnarchiveTaskRequest) (*UnarchiveTaskResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*UnarchiveTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*UnarchiveTaskRequest) when calling interceptor")
+					}
+					return c.callUnarchiveTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*UnarchiveTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*UnarchiveTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *todoServiceJSONClient) callUnarchiveTask(ctx context.Context, in *UnarchiveTaskRequest) (*UnarchiveTaskResponse, error) {
+	out := new(UnarchiveTaskResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[7], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *todoServiceJSONClient) ReorderTask(ctx context.Context, in *ReorderTaskRequest) (*ReorderTaskResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "bakins.todo.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "TodoService")
+	ctx = ctxsetters.WithMethodName(ctx, "ReorderTask")
+	caller := c.callReorderTask
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ReorderTaskRequest) (*ReorderTaskResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ReorderTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ReorderTaskRequest) when calling interceptor")
+					}
+					return c.callReorderTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ReorderTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ReorderTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *todoServiceJSONClient) callReorderTask(ctx context.Context, in *ReorderTaskRequest) (*ReorderTaskResponse, error) {
+	out := new(ReorderTaskResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[8], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *todoServiceJSONClient) ListAuditEntries(ctx context.Context, in *ListAuditEntriesRequest) (*ListAuditEntriesResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "bakins.todo.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "TodoService")
+	ctx = ctxsetters.WithMethodName(ctx, "ListAuditEntries")
+	caller := c.callListAuditEntries
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ListAuditEntriesRequest) (*ListAuditEntriesResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListAuditEntriesRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListAuditEntriesRequest) when calling interceptor")
+					}
+					return c.callListAuditEntries(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListAuditEntriesResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListAuditEntriesResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *todoServiceJSONClient) callListAuditEntries(ctx context.Context, in *ListAuditEntriesRequest) (*ListAuditEntriesResponse, error) {
+	out := new(ListAuditEntriesResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[9], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+// ==========================
+// TodoService Server Handler
+// ==========================
+
+type todoServiceServer struct {
+	TodoService
+	interceptor      twirp.Interceptor
+	hooks            *twirp.ServerHooks
+	pathPrefix       string // prefix for routing
+	jsonSkipDefaults bool   // do not include unpopulated fields (default values) in the response
+	jsonCamelCase    bool   // JSON fields are serialized as lowerCamelCase rather than keeping the original proto names
+}
+
+// NewTodoServiceServer builds a TwirpServer that can be used as an http.Handler to handle
+// HTTP requests that are routed to the right method in the provided svc implementation.
+// The opts are twirp.ServerOption modifiers, for example twirp.WithServerHooks(hooks).
+func NewTodoServiceServer(svc TodoService, opts ...interface{}) TwirpServer {
+	serverOpts := newServerOpts(opts)
+
+	// Using ReadOpt allows backwards and forwads compatibility with new options in the future
+	jsonSkipDefaults := false
+	_ = serverOpts.ReadOpt("jsonSkipDefaults", &jsonSkipDefaults)
+	jsonCamelCase := false
+	_ = serverOpts.ReadOpt("jsonCamelCase", &jsonCamelCase)
+	var pathPrefix string
+	if ok := serverOpts.ReadOpt("pathPrefix", &pathPrefix); !ok {
+		pathPrefix = "/twirp" // default prefix
+	}
+
+	return &todoServiceServer{
+		TodoService:      svc,
+		hooks:            serverOpts.Hooks,
+		interceptor:      twirp.ChainInterceptors(serverOpts.Interceptors...),
+		pathPrefix:       pathPrefix,
+		jsonSkipDefaults: jsonSkipDefaults,
+		jsonCamelCase:    jsonCamelCase,
+	}
+}
+
+// writeError writes an HTTP response with a valid Twirp error format, and triggers hooks.
+// If err is not a twirp.Error, it will get wrapped with twirp.InternalErrorWith(err)
+func (s *todoServiceServer) writeError(ctx context.Context, resp http.ResponseWriter, err error) {
+	writeError(ctx, resp, err, s.hooks)
+}
+
+// handleRequestBodyError is used to handle error when the twirp server cannot read request
+func (s *todoServiceServer) handleRequestBodyError(ctx context.Context, resp http.ResponseWriter, msg string, err error) {
+	if context.Canceled == ctx.Err() {
+		s.writeError(ctx, resp, twirp.NewError(twirp.Canceled, "failed to read request: context canceled"))
+		return
+	}
+	if context.DeadlineExceeded == ctx.Err() {
+		s.writeError(ctx, resp, twirp.NewError(twirp.DeadlineExceeded, "failed to read request: deadline exceeded"))
+		return
+	}
+	s.writeError(ctx, resp, twirp.WrapError(malformedRequestError(msg), err))
+}
+
+// TodoServicePathPrefix is a convenience constant that may identify URL paths.
+// Should be used with caution, it only matches routes generated by Twirp Go clients,
+// with the default "/twirp" prefix and default CamelCase service and method names.
+// More info: https://twitchtv.github.io/twirp/docs/routing.html
+const TodoServicePathPrefix = "/twirp/bakins.todo.v1.TodoService/"
+
+func (s *todoServiceServer) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	ctx = ctxsetters.WithPackageName(ctx, "bakins.todo.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "TodoService")
+	ctx = ctxsetters.WithResponseWriter(ctx, resp)
+
+	var err error
+	ctx, err = callRequestReceived(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	if req.Method != "POST" {
+		msg := fmt.Sprintf("unsupported method %q (only POST is allowed)", req.Method)
+		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+		return
+	}
+
+	// Verify path format: [<prefix>]/<package>.<Service>/<Method>
+	prefix, pkgService, method := parseTwirpPath(req.URL.Path)
+	if pkgService != "bakins.todo.v1.TodoService" {
+		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
+		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+		return
+	}
+	if prefix != s.pathPrefix {
+		msg := fmt.Sprintf("invalid path prefix %q, expected %q, on path %q", prefix, s.pathPrefix, req.URL.Path)
+		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+		return
+	}
+
+	switch method {
+	case "ListTasks":
+		s.serveListTasks(ctx, resp, req)
+		return
+	case "CreateTask":
+		s.serveCreateTask(ctx, resp, req)
+		return
+	case "GetTask":
+		s.serveGetTask(ctx, resp, req)
+		return
+	case "BatchGetTasks":
+		s.serveBatchGetTasks(ctx, resp, req)
+		return
+	case "DeleteTask":
+		s.serveDeleteTask(ctx, resp, req)
+		return
+	case "RestoreTask":
+		s.serveRestoreTask(ctx, resp, req)
+		return
+	case "ArchiveTask":
+		s.serveArchiveTask(ctx, resp, req)
+		return
+	case "UnarchiveTask":
+		s.serveUnarchiveTask(ctx, resp, req)
+		return
+	case "ReorderTask":
+		s.serveReorderTask(ctx, resp, req)
+		return
+	case "ListAuditEntries":
+		s.serveListAuditEntries(ctx, resp, req)
+		return
+	default:
+		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
+		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+		return
+	}
+}
+
+func (s *todoServiceServer) serveListTasks(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveListTasksJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveListTasksProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *todoServiceServer) serveListTasksJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListTasks")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(ListTasksRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.TodoService.ListTasks
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ListTasksRequest) (*ListTasksResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListTasksRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListTasksRequest) when calling interceptor")
+					}
+					return s.TodoService.ListTasks(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListTasksResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListTasksResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ListTasksResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListTasksResponse and nil error while calling ListTasks. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *todoServiceServer) serveListTasksProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListTasks")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(ListTasksRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.TodoService.ListTasks
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ListTasksRequest) (*ListTasksResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListTasksRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListTasksRequest) when calling interceptor")
+					}
+					return s.TodoService.ListTasks(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListTasksResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListTasksResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ListTasksResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListTasksResponse and nil error while calling ListTasks. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *todoServiceServer) serveCreateTask(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveCreateTaskJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveCreateTaskProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *todoServiceServer) serveCreateTaskJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "CreateTask")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(CreateTaskRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.TodoService.CreateTask
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *CreateTaskRequest) (*CreateTaskResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*CreateTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*CreateTaskRequest) when calling interceptor")
+					}
+					return s.TodoService.CreateTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*CreateTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*CreateTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *CreateTaskResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *CreateTaskResponse and nil error while calling CreateTask. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *todoServiceServer) serveCreateTaskProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "CreateTask")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(CreateTaskRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.TodoService.CreateTask
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *CreateTaskRequest) (*CreateTaskResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*CreateTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*CreateTaskRequest) when calling interceptor")
+					}
+					return s.TodoService.CreateTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*CreateTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*CreateTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *CreateTaskResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *CreateTaskResponse and nil error while calling CreateTask. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *todoServiceServer) serveGetTask(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveGetTaskJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveGetTaskProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *todoServiceServer) serveGetTaskJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "GetTask")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(GetTaskRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.TodoService.GetTask
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *GetTaskRequest) (*GetTaskResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GetTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GetTaskRequest) when calling interceptor")
+					}
+					return s.TodoService.GetTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GetTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GetTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *GetTaskResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GetTaskResponse and nil error while calling GetTask. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *todoServiceServer) serveGetTaskProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "GetTask")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(GetTaskRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.TodoService.GetTask
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *GetTaskRequest) (*GetTaskResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GetTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GetTaskRequest) when calling interceptor")
+					}
+					return s.TodoService.GetTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GetTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GetTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *GetTaskResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GetTaskResponse and nil error while calling GetTask. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *todoServiceServer) serveBatchGetTasks(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveBatchGetTasksJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveBatchGetTasksProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *todoServiceServer) serveBatchGetTasksJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "BatchGetTasks")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(BatchGetTasksRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.TodoService.BatchGetTasks
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *BatchGetTasksRequest) (*BatchGetTasksResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*BatchGetTasksRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*BatchGetTasksRequest) when calling interceptor")
+					}
+					return s.TodoService.BatchGetTasks(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*BatchGetTasksResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*BatchGetTasksResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *BatchGetTasksResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *BatchGetTasksResponse and nil error while calling BatchGetTasks. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *todoServiceServer) serveBatchGetTasksProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "BatchGetTasks")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(BatchGetTasksRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.TodoService.BatchGetTasks
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *BatchGetTasksRequest) (*BatchGetTasksResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*BatchGetTasksRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*BatchGetTasksRequest) when calling interceptor")
+					}
+					return s.TodoService.BatchGetTasks(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*BatchGetTasksResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*BatchGetTasksResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *BatchGetTasksResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *BatchGetTasksResponse and nil error while calling BatchGetTasks. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *todoServiceServer) serveDeleteTask(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveDeleteTaskJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveDeleteTaskProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *todoServiceServer) serveDeleteTaskJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "DeleteTask")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(DeleteTaskRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.TodoService.DeleteTask
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *DeleteTaskRequest) (*DeleteTaskResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DeleteTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DeleteTaskRequest) when calling interceptor")
+					}
+					return s.TodoService.DeleteTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DeleteTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DeleteTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *DeleteTaskResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *DeleteTaskResponse and nil error while calling DeleteTask. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *todoServiceServer) serveDeleteTaskProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "DeleteTask")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(DeleteTaskRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.TodoService.DeleteTask
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *DeleteTaskRequest) (*DeleteTaskResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DeleteTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DeleteTaskRequest) when calling interceptor")
+					}
+					return s.TodoService.DeleteTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DeleteTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DeleteTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *DeleteTaskResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *DeleteTaskResponse and nil error while calling DeleteTask. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *todoServiceServer) serveRestoreTask(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveRestoreTaskJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveRestoreTaskProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *todoServiceServer) serveRestoreTaskJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "RestoreTask")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(RestoreTaskRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.TodoService.RestoreTask
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *RestoreTaskRequest) (*RestoreTaskResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RestoreTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RestoreTaskRequest) when calling interceptor")
+					}
+					return s.TodoService.RestoreTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RestoreTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RestoreTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *RestoreTaskResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RestoreTaskResponse and nil error while calling RestoreTask. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *todoServiceServer) serveRestoreTaskProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "RestoreTask")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(RestoreTaskRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.TodoService.RestoreTask
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *RestoreTaskRequest) (*RestoreTaskResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RestoreTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RestoreTaskRequest) when calling interceptor")
+					}
+					return s.TodoService.RestoreTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RestoreTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RestoreTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *RestoreTaskResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RestoreTaskResponse and nil error while calling RestoreTask. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *todoServiceServer) serveArchiveTask(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveArchiveTaskJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveArchiveTaskProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *todoServiceServer) serveArchiveTaskJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ArchiveTask")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(ArchiveTaskRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.TodoService.ArchiveTask
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ArchiveTaskRequest) (*ArchiveTaskResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ArchiveTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ArchiveTaskRequest) when calling interceptor")
+					}
+					return s.TodoService.ArchiveTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ArchiveTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ArchiveTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ArchiveTaskResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ArchiveTaskResponse and nil error while calling ArchiveTask. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *todoServiceServer) serveArchiveTaskProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ArchiveTask")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(ArchiveTaskRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.TodoService.ArchiveTask
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ArchiveTaskRequest) (*ArchiveTaskResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ArchiveTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ArchiveTaskRequest) when calling interceptor")
+					}
+					return s.TodoService.ArchiveTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ArchiveTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ArchiveTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ArchiveTaskResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ArchiveTaskResponse and nil error while calling ArchiveTask. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *todoServiceServer) serveUnarchiveTask(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveUnarchiveTaskJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveUnarchiveTaskProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *todoServiceServer) serveUnarchiveTaskJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "UnarchiveTask")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(UnarchiveTaskRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.TodoService.UnarchiveTask
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *UnarchiveTaskRequest) (*UnarchiveTaskResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*UnarchiveTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*UnarchiveTaskRequest) when calling interceptor")
+					}
+					return s.TodoService.UnarchiveTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*UnarchiveTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*UnarchiveTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *UnarchiveTaskResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *UnarchiveTaskResponse and nil error while calling UnarchiveTask. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *todoServiceServer) serveUnarchiveTaskProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "UnarchiveTask")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(UnarchiveTaskRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.TodoService.UnarchiveTask
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *UnarchiveTaskRequest) (*UnarchiveTaskResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*UnarchiveTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*UnarchiveTaskRequest) when calling interceptor")
+					}
+					return s.TodoService.UnarchiveTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*UnarchiveTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*UnarchiveTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *UnarchiveTaskResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *UnarchiveTaskResponse and nil error while calling UnarchiveTask. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *todoServiceServer) serveReorderTask(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveReorderTaskJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveReorderTaskProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *todoServiceServer) serveReorderTaskJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ReorderTask")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(ReorderTaskRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.TodoService.ReorderTask
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ReorderTaskRequest) (*ReorderTaskResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ReorderTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ReorderTaskRequest) when calling interceptor")
+					}
+					return s.TodoService.ReorderTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ReorderTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ReorderTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ReorderTaskResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ReorderTaskResponse and nil error while calling ReorderTask. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *todoServiceServer) serveReorderTaskProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ReorderTask")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(ReorderTaskRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.TodoService.ReorderTask
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ReorderTaskRequest) (*ReorderTaskResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ReorderTaskRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ReorderTaskRequest) when calling interceptor")
+					}
+					return s.TodoService.ReorderTask(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ReorderTaskResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ReorderTaskResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ReorderTaskResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ReorderTaskResponse and nil error while calling ReorderTask. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *todoServiceServer) serveListAuditEntries(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveListAuditEntriesJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveListAuditEntriesProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *todoServiceServer) serveListAuditEntriesJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListAuditEntries")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(ListAuditEntriesRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.TodoService.ListAuditEntries
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ListAuditEntriesRequest) (*ListAuditEntriesResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListAuditEntriesRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListAuditEntriesRequest) when calling interceptor")
+					}
+					return s.TodoService.ListAuditEntries(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListAuditEntriesResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListAuditEntriesResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ListAuditEntriesResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListAuditEntriesResponse and nil error while calling ListAuditEntries. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *todoServiceServer) serveListAuditEntriesProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListAuditEntries")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(ListAuditEntriesRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.TodoService.ListAuditEntries
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ListAuditEntriesRequest) (*ListAuditEntriesResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListAuditEntriesRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListAuditEntriesRequest) when calling interceptor")
+					}
+					return s.TodoService.ListAuditEntries(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListAuditEntriesResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListAuditEntriesResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ListAuditEntriesResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListAuditEntriesResponse and nil error while calling ListAuditEntries. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *todoServiceServer) ServiceDescriptor() ([]byte, int) {
+	return twirpFileDescriptor0, 0
+}
+
+func (s *todoServiceServer) ProtocGenTwirpVersion() string {
+	return "v8.1.2"
+}
+
+// PathPrefix returns the base service path, in the form: "/<prefix>/<package>.<Service>/"
+// that is everything in a Twirp route except for the <Method>. This can be used for routing,
+// for example to identify the requests that are targeted to this service in a mux.
+func (s *todoServiceServer) PathPrefix() string {
+	return baseServicePath(s.pathPrefix, "bakins.todo.v1", "TodoService")
+}
+
+// =====
+// Utils
+// =====
+
+// HTTPClient is the interface used by generated clients to send HTTP requests.
+// It is fulfilled by *(net/http).Client, which is sufficient for most users.
+// Users can provide their own implementation for special retry policies.
+//
+// HTTPClient implementations should not follow redirects. Redirects are
+// automatically disabled if *(net/http).Client is passed to client
+// constructors. See the withoutRedirects function in this file for more
+// details.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// TwirpServer is the interface generated server structs will support: they're
+// HTTP handlers with additional methods for accessing metadata about the
+// service. Those accessors are a low-level API for building reflection tools.
+// Most people can think of TwirpServers as just http.Handlers.
+type TwirpServer interface {
+	http.Handler
+
+	// ServiceDescriptor returns gzipped bytes describing the .proto file that
+	// this service was generated from. Once unzipped, the bytes can be
+	// unmarshalled as a
+	// google.golang.org/protobuf/types/descriptorpb.FileDescriptorProto.
+	//
+	// The returned integer is the index of this particular service within that
+	// FileDescriptorProto's 'Service' slice of ServiceDescriptorProtos. This is a
+	// low-level field, expected to be used for reflection.
+	ServiceDescriptor() ([]byte, int)
+
+	// ProtocGenTwirpVersion is the semantic version string of the version of
+	// twirp used to generate this file.
+	ProtocGenTwirpVersion() string
+
+	// PathPrefix returns the HTTP URL path prefix for all methods handled by this
+	// service. This can be used with an HTTP mux to route Twirp requests.
+	// The path prefix is in the form: "/<prefix>/<package>.<Service>/"
+	// that is, everything in a Twirp route except for the <Method> at the end.
+	PathPrefix() string
+}
+
+func newServerOpts(opts []interface{}) *twirp.ServerOptions {
+	serverOpts := &twirp.ServerOptions{}
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case twirp.ServerOption:
+			o(serverOpts)
+		case *twirp.ServerHooks: // backwards compatibility, allow to specify hooks as an argument
+			twirp.WithServerHooks(o)(serverOpts)
+		case nil: // backwards compatibility, allow nil value for the argument
+			continue
+		default:
+			panic(fmt.Sprintf("Invalid option type %T, please use a twirp.ServerOption", o))
+		}
+	}
+	return serverOpts
+}
+
+// WriteError writes an HTTP response with a valid Twirp error format (code, msg, meta).
+// Useful outside of the Twirp server (e.g. http middleware), but does not trigger hooks.
+// If err is not a twirp.Error, it will get wrapped with twirp.InternalErrorWith(err)
+func WriteError(resp http.ResponseWriter, err error) {
+	writeError(context.Background(), resp, err, nil)
+}
+
+// writeError writes Twirp errors in the response and triggers hooks.
+func writeError(ctx context.Context, resp http.ResponseWriter, err error, hooks *twirp.ServerHooks) {
+	// Convert to a twirp.Error. Non-twirp errors are converted to internal errors.
+	var twerr twirp.Error
+	if !errors.As(err, &twerr) {
+		twerr = twirp.InternalErrorWith(err)
+	}
+
+	statusCode := twirp.ServerHTTPStatusFromErrorCode(twerr.Code())
+	ctx = ctxsetters.WithStatusCode(ctx, statusCode)
+	ctx = callError(ctx, hooks, twerr)
+
+	respBody := marshalErrorToJSON(twerr)
+
+	resp.Header().Set("Content-Type", "application/json") // Error responses are always JSON
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBody)))
+	resp.WriteHeader(statusCode) // set HTTP status code and send response
+
+	_, writeErr := resp.Write(respBody)
+	if writeErr != nil {
+		// We have three options here. We could log the error, call the Error
+		// hook, or just silently ignore the error.
+		//
+		// Logging is unacceptable because we don't have a user-controlled
+		// logger; writing out to stderr without permission is too rude.
+		//
+		// Calling the Error hook would confuse users: it would mean the Error
+		// hook got called twice for one request, which is likely to lead to
+		// duplicated log messages and metrics, no matter how well we document
+		// the behavior.
+		//
+		// Silently ignoring the error is our least-bad option. It's highly
+		// likely that the connection is broken and the original 'err' says
+		// so anyway.
+		_ = writeErr
+	}
+
+	callResponseSent(ctx, hooks)
+}
+
+// sanitizeBaseURL parses the the baseURL, and adds the "http" scheme if needed.
+// If the URL is unparsable, the baseURL is returned unchaged.
+func sanitizeBaseURL(baseURL string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return baseURL // invalid URL will fail later when making requests
+	}
+	if u.Scheme == "" {
+		u.Scheme = "http"
+	}
+	return u.String()
+}
+
+// baseServicePath composes the path prefix for the service (without <Method>).
+// e.g.: baseServicePath("/twirp", "my.pkg", "MyService")
+//       returns => "/twirp/my.pkg.MyService/"
+// e.g.: baseServicePath("", "", "MyService")
+//       returns => "/MyService/"
+func baseServicePath(prefix, pkg, service string) string {
+	fullServiceName := service
+	if pkg != "" {
+		fullServiceName = pkg + "." + service
+	}
+	return path.Join("/", prefix, fullServiceName) + "/"
+}
+
+// parseTwirpPath extracts path components form a valid Twirp route.
+// Expected format: "[<prefix>]/<package>.<Service>/<Method>"
+// e.g.: prefix, pkgService, method := parseTwirpPath("/twirp/pkg.Svc/MakeHat")
+func parseTwirpPath(path string) (string, string, string) {
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 {
+		return "", "", ""
+	}
+	method := parts[len(parts)-1]
+	pkgService := parts[len(parts)-2]
+	prefix := strings.Join(parts[0:len(parts)-2], "/")
+	return prefix, pkgService, method
+}
+
+// getCustomHTTPReqHeaders retrieves a copy of any headers that are set in
+// a context through the twirp.WithHTTPRequestHeaders function.
+// If there are no headers set, or if they have the wrong type, nil is returned.
+func getCustomHTTPReqHeaders(ctx context.Context) http.Header {
+	header, ok := twirp.HTTPRequestHeaders(ctx)
+	if !ok || header == nil {
+		return nil
+	}
+	copied := make(http.Header)
+	for k, vv := range header {
+		if vv == nil {
+			copied[k] = nil
+			continue
+		}
+		copied[k] = make([]string, len(vv))
+		copy(copied[k], vv)
+	}
+	return copied
+}
+
+// newRequest makes an http.Request from a client, adding common headers.
+func newRequest(ctx context.Context, url string, reqBody io.Reader, contentType string) (*http.Request, error) {
+	req, err := http.NewRequest("POST", url, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if customHeader := getCustomHTTPReqHeaders(ctx); customHeader != nil {
+		req.Header = customHeader
+	}
+	req.Header.Set("Accept", contentType)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Twirp-Version", "v8.1.2")
+	return req, nil
+}
+
+// JSON serialization for errors
+type twerrJSON struct {
+	Code string            `json:"code"`
+	Msg  string            `json:"msg"`
+	Meta map[string]string `json:"meta,omitempty"`
+}
+
+// marshalErrorToJSON returns JSON from a twirp.Error, that can be used as HTTP error response body.
+// If serialization fails, it will use a descriptive Internal error instead.
+func marshalErrorToJSON(twerr twirp.Error) []byte {
+	// make sure that msg is not too large
+	msg := twerr.Msg()
+	if len(msg) > 1e6 {
+		msg = msg[:1e6]
+	}
+
+	tj := twerrJSON{
+		Code: string(twerr.Code()),
+		Msg:  msg,
+		Meta: twerr.MetaMap(),
+	}
+
+	buf, err := json.Marshal(&tj)
+	if err != nil {
+		buf = []byte("{\"type\": \"" + twirp.Internal + "\", \"msg\": \"There was an error but it could not be serialized into JSON\"}") // fallback
+	}
+
+	return buf
+}
+
+// errorFromResponse builds a twirp.Error from a non-200 HTTP response.
+// If the response has a valid serialized Twirp error, then it's returned.
+// If not, the response status code is used to generate a similar twirp
+// error. See twirpErrorFromIntermediary for more info on intermediary errors.
+func errorFromResponse(resp *http.Response) twirp.Error {
+	statusCode := resp.StatusCode
+	statusText := http.StatusText(statusCode)
+
+	if isHTTPRedirect(statusCode) {
+		// Unexpected redirect: it must be an error from an intermediary.
+		// Twirp clients don't follow redirects automatically, Twirp only handles
+		// POST requests, redirects should only happen on GET and HEAD requests.
+		location := resp.Header.Get("Location")
+		msg := fmt.Sprintf("unexpected HTTP status code %d %q received, Location=%q", statusCode, statusText, location)
+		return twirpErrorFromIntermediary(statusCode, msg, location)
+	}
+
+	respBodyBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return wrapInternal(err, "failed to read server error response body")
+	}
+
+	var tj twerrJSON
+	dec := json.NewDecoder(bytes.NewReader(respBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&tj); err != nil || tj.Code == "" {
+		// Invalid JSON response; it must be an error from an intermediary.
+		msg := fmt.Sprintf("Error from intermediary with HTTP status code %d %q", statusCode, statusText)
+		return twirpErrorFromIntermediary(statusCode, msg, string(respBodyBytes))
+	}
+
+	errorCode := twirp.ErrorCode(tj.Code)
+	if !twirp.IsValidErrorCode(errorCode) {
+		msg := "invalid type returned from server error response: " + tj.Code
+		return twirp.InternalError(msg).WithMeta("body", string(respBodyBytes))
+	}
+
+	twerr := twirp.NewError(errorCode, tj.Msg)
+	for k, v := range tj.Meta {
+		twerr = twerr.WithMeta(k, v)
+	}
+	return twerr
+}
+
+// twirpErrorFromIntermediary maps HTTP errors from non-twirp sources to twirp errors.
+// The mapping is similar to gRPC: https://github.com/grpc/grpc/blob/master/doc/http-grpc-status-mapping.md.
+// Returned twirp Errors have some additional metadata for inspection.
+func twirpErrorFromIntermediary(status int, msg string, bodyOrLocation string) twirp.Error {
+	var code twirp.ErrorCode
+	if isHTTPRedirect(status) { // 3xx
+		code = twirp.Internal
+	} else {
+		switch status {
+		case 400: // Bad Request
+			code = twirp.Internal
+		case 401: // Unauthorized
+			code = twirp.Unauthenticated
+		case 403: // Forbidden
+			code = twirp.PermissionDenied
+		case 404: // Not Found
+			code = twirp.BadRoute
+		case 429: // Too Many Requests
+			code = twirp.ResourceExhausted
+		case 502, 503, 504: // Bad Gateway, Service Unavailable, Gateway Timeout
+			code = twirp.Unavailable
+		default: // All other codes
+			code = twirp.Unknown
+		}
+	}
+
+	twerr := twirp.NewError(code, msg)
+	twerr = twerr.WithMeta("http_error_from_intermediary", "true") // to easily know if this error was from intermediary
+	twerr = twerr.WithMeta("status_code", strconv.Itoa(status))
+	if isHTTPRedirect(status) {
+		twerr = twerr.WithMeta("location", bodyOrLocation)
+	} else {
+		twerr = twerr.WithMeta("body", bodyOrLocation)
+	}
+	return twerr
+}
+
+func isHTTPRedirect(status int) bool {
+	return status >= 300 && status <= 399
+}
+
+// wrapInternal wraps an error with a prefix as an Internal error.
+// The original error cause is accessible by github.com/pkg/errors.Cause.
+func wrapInternal(err error, prefix string) twirp.Error {
+	return twirp.InternalErrorWith(&wrappedError{prefix: prefix, cause: err})
+}
+
+type wrappedError struct {
+	prefix string
+	cause  error
+}
+
+func (e *wrappedError) Error() string { return e.prefix + ": " + e.cause.Error() }
+func (e *wrappedError) Unwrap() error { return e.cause } // for go1.13 + errors.Is/As
+func (e *wrappedError) Cause() error  { return e.cause } // for github.com/pkg/errors
+
+// ensurePanicResponses makes sure that rpc methods causing a panic still result in a Twirp Internal
+// error response (status 500), and error hooks are properly called with the panic wrapped as an error.
+// The panic is re-raised so it can be handled normally with middleware.
+func ensurePanicResponses(ctx context.Context, resp http.ResponseWriter, hooks *twirp.ServerHooks) {
+	if r := recover(); r != nil {
+		// Wrap the panic as an error so it can be passed to error hooks.
+		// The original error is accessible from error hooks, but not visible in the response.
+		err := errFromPanic(r)
+		twerr := &internalWithCause{msg: "Internal service panic", cause: err}
+		// Actually write the error
+		writeError(ctx, resp, twerr, hooks)
+		// If possible, flush the error to the wire.
+		f, ok := resp.(http.Flusher)
+		if ok {
+			f.Flush()
+		}
+
+		panic(r)
+	}
+}
+
+// errFromPanic returns the typed error if the recovered panic is an error, otherwise formats as error.
+func errFromPanic(p interface{}) error {
+	if err, ok := p.(error); ok {
+		return err
+	}
+	return fmt.Errorf("panic: %v", p)
+}
+
+// internalWithCause is a Twirp Internal error wrapping an original error cause,
+// but the original error message is not exposed on Msg(). The original error
+// can be checked with go1.13+ errors.Is/As, and also by (github.com/pkg/errors).Unwrap
+type internalWithCause struct {
+	msg   string
+	cause error
+}
+
+func (e *internalWithCause) Unwrap() error                               { return e.cause } // for go1.13 + errors.Is/As
+func (e *internalWithCause) Cause() error                                { return e.cause } // for github.com/pkg/errors
+func (e *internalWithCause) Error() string                               { return e.msg + ": " + e.cause.Error() }
+func (e *internalWithCause) Code() twirp.ErrorCode                       { return twirp.Internal }
+func (e *internalWithCause) Msg() string                                 { return e.msg }
+func (e *internalWithCause) Meta(key string) string                      { return "" }
+func (e *internalWithCause) MetaMap() map[string]string                  { return nil }
+func (e *internalWithCause) WithMeta(key string, val string) twirp.Error { return e }
+
+// malformedRequestError is used when the twirp server cannot unmarshal a request
+func malformedRequestError(msg string) twirp.Error {
+	return twirp.NewError(twirp.Malformed, msg)
+}
+
+// badRouteError is used when the twirp server cannot route a request
+func badRouteError(msg string, method, url string) twirp.Error {
+	err := twirp.NewError(twirp.BadRoute, msg)
+	err = err.WithMeta("twirp_invalid_route", method+" "+url)
+	return err
+}
+
+// withoutRedirects makes sure that the POST request can not be redirected.
+// The standard library will, by default, redirect requests (including POSTs) if it gets a 302 or
+// 303 response, and also 301s in go1.8. It redirects by making a second request, changing the
+// method to GET and removing the body. This produces very confusing error messages, so instead we
+// set a redirect policy that always errors. This stops Go from executing the redirect.
+//
+// We have to be a little careful in case the user-provided http.Client has its own CheckRedirect
+// policy - if so, we'll run through that policy first.
+//
+// Because this requires modifying the http.Client, we make a new copy of the client and return it.
+func withoutRedirects(in *http.Client) *http.Client {
+	copy := *in
+	copy.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if in.CheckRedirect != nil {
+			// Run the input's redirect if it exists, in case it has side effects, but ignore any error it
+			// returns, since we want to use ErrUseLastResponse.
+			err := in.CheckRedirect(req, via)
+			_ = err // Silly, but this makes sure generated code passes errcheck -blank, which some people use.
+		}
+		return http.ErrUseLastResponse
+	}
+	return &copy
+}
+
+// doProtobufRequest makes a Protobuf request to the remote Twirp service.
+func doProtobufRequest(ctx context.Context, client HTTPClient, hooks *twirp.ClientHooks, url string, in, out proto.Message) (_ context.Context, err error) {
+	reqBodyBytes, err := proto.Marshal(in)
+	if err != nil {
+		return ctx, wrapInternal(err, "failed to marshal proto request")
+	}
+	reqBody := bytes.NewBuffer(reqBodyBytes)
+	if err = ctx.Err(); err != nil {
+		return ctx, wrapInternal(err, "aborted because context was done")
+	}
+
+	req, err := newRequest(ctx, url, reqBody, "application/protobuf")
+	if err != nil {
+		return ctx, wrapInternal(err, "could not build request")
+	}
+	ctx, err = callClientRequestPrepared(ctx, hooks, req)
+	if err != nil {
+		return ctx, err
+	}
+
+	req = req.WithContext(ctx)
+	resp, err := client.Do(req)
+	if err != nil {
+		return ctx, wrapInternal(err, "failed to do request")
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if err = ctx.Err(); err != nil {
+		return ctx, wrapInternal(err, "aborted because context was done")
+	}
+
+	if resp.StatusCode != 200 {
+		return ctx, errorFromResponse(resp)
+	}
+
+	respBodyBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return ctx, wrapInternal(err, "failed to read response body")
+	}
+	if err = ctx.Err(); err != nil {
+		return ctx, wrapInternal(err, "aborted because context was done")
+	}
+
+	if err = proto.Unmarshal(respBodyBytes, out); err != nil {
+		return ctx, wrapInternal(err, "failed to unmarshal proto response")
+	}
+	return ctx, nil
+}
+
+// doJSONRequest makes a JSON request to the remote Twirp service.
+func doJSONRequest(ctx context.Context, client HTTPClient, hooks *twirp.ClientHooks, url string, in, out proto.Message) (_ context.Context, err error) {
+	marshaler := &protojson.MarshalOptions{UseProtoNames: true}
+	reqBytes, err := marshaler.Marshal(in)
+	if err != nil {
+		return ctx, wrapInternal(err, "failed to marshal json request")
+	}
+	if err = ctx.Err(); err != nil {
+		return ctx, wrapInternal(err, "aborted because context was done")
+	}
+
+	req, err := newRequest(ctx, url, bytes.NewReader(reqBytes), "application/json")
+	if err != nil {
+		return ctx, wrapInternal(err, "could not build request")
+	}
+	ctx, err = callClientRequestPrepared(ctx, hooks, req)
+	if err != nil {
+		return ctx, err
+	}
+
+	req = req.WithContext(ctx)
+	resp, err := client.Do(req)
+	if err != nil {
+		return ctx, wrapInternal(err, "failed to do request")
+	}
+
+	defer func() {
+		cerr := resp.Body.Close()
+		if err == nil && cerr != nil {
+			err = wrapInternal(cerr, "failed to close response body")
+		}
+	}()
+
+	if err = ctx.Err(); err != nil {
+		return ctx, wrapInternal(err, "aborted because context was done")
+	}
+
+	if resp.StatusCode != 200 {
+		return ctx, errorFromResponse(resp)
+	}
+
+	d := json.NewDecoder(resp.Body)
+	rawRespBody := json.RawMessage{}
+	if err := d.Decode(&rawRespBody); err != nil {
+		return ctx, wrapInternal(err, "failed to unmarshal json response")
+	}
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawRespBody, out); err != nil {
+		return ctx, wrapInternal(err, "failed to unmarshal json response")
+	}
+	if err = ctx.Err(); err != nil {
+		return ctx, wrapInternal(err, "aborted because context was done")
+	}
+	return ctx, nil
+}
+
+// Call twirp.ServerHooks.RequestReceived if the hook is available
+func callRequestReceived(ctx context.Context, h *twirp.ServerHooks) (context.Context, error) {
+	if h == nil || h.RequestReceived == nil {
+		return ctx, nil
+	}
+	return h.RequestReceived(ctx)
+}
+
+// Call twirp.ServerHooks.RequestRouted if the hook is available
+func callRequestRouted(ctx context.Context, h *twirp.ServerHooks) (context.Context, error) {
+	if h == nil || h.RequestRouted == nil {
+		return ctx, nil
+	}
+	return h.RequestRouted(ctx)
+}
+
+// Call twirp.ServerHooks.ResponsePrepared if the hook is available
+func callResponsePrepared(ctx context.Context, h *twirp.ServerHooks) context.Context {
+	if h == nil || h.ResponsePrepared == nil {
+		return ctx
+	}
+	return h.ResponsePrepared(ctx)
+}
+
+// Call twirp.ServerHooks.ResponseSent if the hook is available
+func callResponseSent(ctx context.Context, h *twirp.ServerHooks) {
+	if h == nil || h.ResponseSent == nil {
+		return
+	}
+	h.ResponseSent(ctx)
+}
+
+// Call twirp.ServerHooks.Error if the hook is available
+func callError(ctx context.Context, h *twirp.ServerHooks, err twirp.Error) context.Context {
+	if h == nil || h.Error == nil {
+		return ctx
+	}
+	return h.Error(ctx, err)
+}
+
+func callClientResponseReceived(ctx context.Context, h *twirp.ClientHooks) {
+	if h == nil || h.ResponseReceived == nil {
+		return
+	}
+	h.ResponseReceived(ctx)
+}
+
+func callClientRequestPrepared(ctx context.Context, h *twirp.ClientHooks, req *http.Request) (context.Context, error) {
+	if h == nil || h.RequestPrepared == nil {
+		return ctx, nil
+	}
+	return h.RequestPrepared(ctx, req)
+}
+
+func callClientError(ctx context.Context, h *twirp.ClientHooks, err twirp.Error) {
+	if h == nil || h.Error == nil {
+		return
+	}
+	h.Error(ctx, err)
+}
+
+var twirpFileDescriptor0 = []byte{
+	// 941 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x56, 0x5d, 0x6f, 0xe3, 0x44,
+	0x14, 0x95, 0xf3, 0x51, 0xc7, 0x37, 0x6a, 0x9a, 0xce, 0xa6, 0xac, 0x31, 0x12, 0x1b, 0x5c, 0xaa,
+	0xcd, 0xae, 0xd8, 0x44, 0x94, 0x15, 0x2f, 0x48, 0xac, 0x5a, 0x40, 0x65, 0x01, 0xb1, 0x8b, 0xb7,
+	0xec, 0x03, 0x02, 0x45, 0xae, 0xe7, 0x92, 0x8c, 0xd2, 0xd8, 0xc6, 0x9e, 0x94, 0xe6, 0x8d, 0x3f,
+	0xc3, 0x3b, 0x12, 0xcf, 0xfc, 0x37, 0x34, 0xe3, 0xb1, 0xe3, 0xd8, 0xa9, 0xb3, 0x91, 0xfa, 0x14,
+	0xcf, 0x9d, 0x33, 0x67, 0xce, 0xbd, 0x77, 0xe6, 0x4c, 0xa0, 0x1b, 0x46, 0x01, 0x0f, 0x46, 0x3c,
+	0xa0, 0xc1, 0x50, 0x7e, 0x92, 0xce, 0x95, 0x3b, 0x63, 0x7e, 0x3c, 0x94, 0xa1, 0x9b, 0x4f, 0xad,
+	0x47, 0x93, 0x20, 0x98, 0x5c, 0xe3, 0x48, 0xce, 0x5e, 0x2d, 0x7e, 0x1f, 0x71, 0x36, 0xc7, 0x98,
+	0xbb, 0xf3, 0x30, 0x59, 0x60, 0xff, 0x57, 0x83, 0xc6, 0xa5, 0x1b, 0xcf, 0x48, 0x07, 0x6a, 0x8c,
+	0x9a, 0x5a, 0x5f, 0x1b, 0x34, 0x9c, 0x1a, 0xa3, 0xe4, 0x39, 0xe8, 0x5e, 0x84, 0x2e, 0x47, 0x6a,
+	0xd6, 0xfa, 0xda, 0xa0, 0x7d, 0x6a, 0x0d, 0x13, 0xae, 0x61, 0xca, 0x35, 0xbc, 0x4c, 0xb9, 0x9c,
+	0x14, 0x4a, 0x7a, 0xd0, 0xe4, 0x8c, 0x5f, 0xa3, 0x59, 0xef, 0x6b, 0x03, 0xc3, 0x49, 0x06, 0xa4,
+	0x0f, 0x6d, 0x8a, 0xb1, 0x17, 0xb1, 0x90, 0xb3, 0xc0, 0x37, 0x1b, 0x72, 0x2e, 0x1f, 0x22, 0x04,
+	0x1a, 0xdc, 0x9d, 0xc4, 0x66, 0xb3, 0x5f, 0x1f, 0x18, 0x8e, 0xfc, 0x16, 0x0a, 0x28, 0x5e, 0xa3,
+	0x50, 0xb0, 0xb7, 0x5d, 0x81, 0x82, 0x92, 0x0f, 0xc0, 0x08, 0xdd, 0x08, 0x7d, 0x3e, 0x66, 0xd4,
+	0xd4, 0x65, 0x3a, 0xad, 0x24, 0xf0, 0x92, 0x92, 0xcf, 0xa1, 0xe5, 0x46, 0xde, 0x94, 0xdd, 0x20,
+	0x35, 0x5b, 0x5b, 0x39, 0x33, 0x2c, 0x31, 0x41, 0xbf, 0xc1, 0x28, 0x16, 0xe2, 0x0d, 0x49, 0x99,
+	0x0e, 0xed, 0x7f, 0x34, 0xe8, 0xfe, 0xc0, 0x62, 0x2e, 0x6a, 0x18, 0x3b, 0xf8, 0xc7, 0x02, 0x63,
+	0x4e, 0xde, 0x87, 0x56, 0x10, 0x51, 0x8c, 0xc6, 0x57, 0x4b, 0x59, 0x51, 0xc3, 0xd1, 0xe5, 0xf8,
+	0x7c, 0x49, 0xba, 0x50, 0xe7, 0xee, 0x44, 0x96, 0xd4, 0x70, 0xc4, 0x27, 0x79, 0x0c, 0x07, 0xcc,
+	0xf7, 0xae, 0x17, 0x14, 0xc7, 0x69, 0xba, 0xa2, 0x78, 0x2d, 0xa7, 0xa3, 0xc2, 0x5f, 0x6f, 0xca,
+	0xac, 0x51, 0xc8, 0xec, 0x09, 0x74, 0x53, 0x96, 0x2c, 0xc3, 0xa6, 0xa4, 0x49, 0xd9, 0xcf, 0x54,
+	0xd8, 0x7e, 0x01, 0x87, 0x39, 0xc5, 0x71, 0x18, 0xf8, 0x31, 0x92, 0xa7, 0xd0, 0xe4, 0x22, 0x60,
+	0x6a, 0xfd, 0xfa, 0xa0, 0x7d, 0xda, 0x1b, 0xae, 0x1f, 0xa4, 0xa1, 0x40, 0x3b, 0x09, 0xc4, 0xfe,
+	0x5b, 0x83, 0xc3, 0xaf, 0x64, 0xc3, 0x65, 0x54, 0x25, 0x9d, 0xb5, 0x5e, 0xab, 0x68, 0x7d, 0xed,
+	0xee, 0xd6, 0xd7, 0x73, 0xad, 0xaf, 0x4c, 0x55, 0x14, 0x8c, 0xe2, 0x3c, 0x0c, 0x38, 0xfa, 0xde,
+	0x72, 0x3c, 0xc3, 0xa5, 0xcc, 0xd4, 0x70, 0x3a, 0xb9, 0xf0, 0xf7, 0xb8, 0xb4, 0xbf, 0x04, 0x92,
+	0x97, 0xa9, 0x32, 0x1d, 0x88, 0xfd, 0xe2, 0x99, 0x94, 0x79, 0x57, 0xa2, 0x12, 0x61, 0x5f, 0x40,
+	0xe7, 0x02, 0x79, 0x3e, 0xc7, 0xe2, 0x25, 0x39, 0x81, 0xb4, 0x49, 0xe3, 0x29, 0xa3, 0x14, 0x93,
+	0x04, 0x5b, 0xce, 0xbe, 0x8a, 0x7e, 0x2b, 0x83, 0xf6, 0x17, 0x70, 0x90, 0x11, 0xed, 0xac, 0x62,
+	0x00, 0xbd, 0x73, 0x97, 0x7b, 0xd3, 0x0b, 0x5c, 0x3f, 0x64, 0x5d, 0xa8, 0x33, 0x9a, 0xf4, 0xab,
+	0xe1, 0x88, 0x4f, 0xfb, 0x37, 0x38, 0x2a, 0x20, 0x77, 0x6f, 0xae, 0x38, 0xea, 0x73, 0x16, 0xc7,
+	0xcc, 0x17, 0x87, 0x54, 0x50, 0xa7, 0x43, 0xfb, 0x47, 0x38, 0x4c, 0x8e, 0x62, 0x55, 0x45, 0x9e,
+	0x40, 0x17, 0x6f, 0x43, 0xf4, 0x38, 0xd2, 0x71, 0x7a, 0x65, 0x6a, 0x72, 0xf6, 0x20, 0x8d, 0xbf,
+	0x55, 0x57, 0xa7, 0x07, 0x24, 0xcf, 0x97, 0x68, 0xb5, 0x5f, 0x01, 0x71, 0x30, 0xe6, 0x41, 0x74,
+	0x5f, 0xdb, 0xbc, 0x80, 0x07, 0x6b, 0x84, 0x3b, 0x37, 0xe0, 0x15, 0x10, 0x75, 0x77, 0xee, 0x4f,
+	0xd1, 0x1a, 0xe1, 0xce, 0x8a, 0x7e, 0x82, 0xde, 0xcf, 0xbe, 0x7b, 0xaf, 0x9a, 0xce, 0xe0, 0xa8,
+	0x40, 0xb9, 0xb3, 0xaa, 0x99, 0xe8, 0x9c, 0xf4, 0xb9, 0x2a, 0x4d, 0x16, 0xb4, 0xc2, 0x20, 0x66,
+	0x99, 0x1b, 0xec, 0x3b, 0xd9, 0x78, 0xa3, 0xde, 0xfa, 0x66, 0xbd, 0x47, 0xf0, 0x60, 0x6d, 0x33,
+	0x75, 0x7a, 0xfe, 0xd5, 0x00, 0xce, 0x16, 0x94, 0xf1, 0x6f, 0x7c, 0x1e, 0x2d, 0x4b, 0x9b, 0x3f,
+	0x04, 0x5d, 0x48, 0x15, 0xae, 0x92, 0xd4, 0x61, 0x4f, 0x0c, 0x5f, 0x52, 0xf2, 0x1e, 0xec, 0xb9,
+	0x1e, 0x4f, 0xf7, 0x33, 0x1c, 0x35, 0x12, 0xa6, 0xe6, 0x7a, 0x3c, 0x88, 0xd4, 0x9b, 0x95, 0x0c,
+	0xf2, 0x6f, 0x63, 0xf3, 0xdd, 0xdf, 0x46, 0x13, 0x74, 0x6f, 0xea, 0xfa, 0x13, 0x8c, 0xe5, 0x7b,
+	0x66, 0x38, 0xe9, 0xd0, 0x3e, 0x85, 0x87, 0xc2, 0x91, 0x33, 0xe1, 0x0c, 0xb3, 0x5b, 0x9e, 0x53,
+	0xac, 0xe5, 0x15, 0xdb, 0xaf, 0xc1, 0x2c, 0xaf, 0x51, 0x3d, 0x7b, 0x0e, 0x3a, 0x26, 0x21, 0x75,
+	0xe3, 0xad, 0x62, 0xdb, 0x56, 0x35, 0x72, 0x52, 0xe8, 0xe9, 0x5f, 0x3a, 0xb4, 0x2f, 0x03, 0x1a,
+	0xbc, 0xc1, 0xe8, 0x86, 0x79, 0x48, 0x5e, 0x83, 0x91, 0xbd, 0x13, 0xa4, 0x5f, 0x64, 0x28, 0x3e,
+	0x7a, 0xd6, 0x47, 0x15, 0x08, 0xa5, 0xeb, 0x0d, 0xc0, 0xca, 0x90, 0x49, 0x69, 0x41, 0xe9, 0x4d,
+	0xb1, 0xec, 0x2a, 0x88, 0x22, 0xfd, 0x0e, 0x74, 0x65, 0x78, 0xe4, 0xc3, 0x22, 0x7c, 0xdd, 0xbe,
+	0xad, 0x47, 0x77, 0xce, 0x2b, 0xae, 0x5f, 0x61, 0x7f, 0xcd, 0x41, 0xc9, 0xc7, 0xc5, 0x15, 0x9b,
+	0xac, 0xd8, 0x3a, 0xd9, 0x82, 0x5a, 0xa5, 0xbf, 0x32, 0xbc, 0x72, 0xfa, 0x25, 0x73, 0xb5, 0xec,
+	0x2a, 0x88, 0x22, 0x7d, 0x0b, 0xed, 0x9c, 0xbd, 0x91, 0xd2, 0x92, 0xb2, 0x99, 0x5a, 0xc7, 0x95,
+	0x98, 0x15, 0x6f, 0xce, 0xa4, 0xca, 0xbc, 0x65, 0x4b, 0xb4, 0x8e, 0x2b, 0x31, 0xab, 0x12, 0xaf,
+	0x19, 0x4d, 0xb9, 0xc4, 0x9b, 0xac, 0xcd, 0x3a, 0xd9, 0x82, 0xca, 0x57, 0x23, 0xb3, 0x85, 0x4d,
+	0xd5, 0x28, 0x1a, 0x94, 0x75, 0x5c, 0x89, 0x51, 0xbc, 0x98, 0xfc, 0xcb, 0xcb, 0xdf, 0x36, 0xf2,
+	0x78, 0xd3, 0x81, 0xdf, 0x70, 0x87, 0xad, 0xc1, 0x76, 0x60, 0xb2, 0xcd, 0xf9, 0x27, 0xbf, 0x3c,
+	0x9d, 0x30, 0x3e, 0x5d, 0x5c, 0x0d, 0xbd, 0x60, 0x3e, 0x4a, 0x56, 0x8d, 0xf8, 0x9f, 0x2c, 0x0a,
+	0x9f, 0x89, 0xb5, 0xcf, 0xf0, 0xd6, 0x9d, 0x87, 0xd9, 0xdf, 0xf9, 0x3d, 0xf9, 0xf3, 0xd9, 0xff,
+	0x01, 0x00, 0x00, 0xff, 0xff, 0x07, 0xb5, 0x3c, 0x33, 0x07, 0x0c, 0x00, 0x00,
+}
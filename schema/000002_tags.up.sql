@@ -0,0 +1,12 @@
+CREATE TABLE tags (
+    id INTEGER PRIMARY KEY ASC,
+    name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE task_tags (
+    task_id INTEGER NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (task_id, tag_id)
+);
+
+CREATE INDEX task_tags_tag_id ON task_tags (tag_id);
@@ -0,0 +1 @@
+ALTER TABLE tasks ADD COLUMN deleted_at DATETIME;
@@ -0,0 +1,21 @@
+-- sqlite cannot drop a column used in a foreign key, so the table is rebuilt.
+-- Dropping tasks cascades to task_tags, so those rows are kept aside.
+CREATE TEMP TABLE task_tags_backup AS SELECT task_id, tag_id FROM task_tags;
+
+CREATE TABLE tasks_new (
+    id INTEGER PRIMARY KEY ASC,
+    created DATETIME,
+    title TEXT,
+    description TEXT,
+    deleted_at DATETIME
+);
+
+INSERT INTO tasks_new (id, created, title, description, deleted_at)
+SELECT id, created, title, description, deleted_at FROM tasks;
+
+DROP INDEX tasks_parent_id;
+DROP TABLE tasks;
+ALTER TABLE tasks_new RENAME TO tasks;
+
+INSERT INTO task_tags (task_id, tag_id) SELECT task_id, tag_id FROM task_tags_backup;
+DROP TABLE task_tags_backup;
@@ -0,0 +1,3 @@
+ALTER TABLE tasks ADD COLUMN owner TEXT NOT NULL DEFAULT 'local';
+
+CREATE INDEX tasks_owner ON tasks (owner);
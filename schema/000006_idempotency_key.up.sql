@@ -0,0 +1,3 @@
+ALTER TABLE tasks ADD COLUMN idempotency_key TEXT;
+
+CREATE UNIQUE INDEX tasks_owner_idempotency_key ON tasks (owner, idempotency_key);
@@ -0,0 +1 @@
+ALTER TABLE tasks ADD COLUMN archived_at DATETIME;
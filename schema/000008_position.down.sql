@@ -0,0 +1,3 @@
+DROP INDEX tasks_owner_position;
+
+ALTER TABLE tasks DROP COLUMN position;
@@ -0,0 +1,7 @@
+-- positions are fractional so a task can be moved between two others without
+-- renumbering the list.
+ALTER TABLE tasks ADD COLUMN position REAL NOT NULL DEFAULT 0;
+
+UPDATE tasks SET position = id;
+
+CREATE INDEX tasks_owner_position ON tasks (owner, position);
@@ -0,0 +1,11 @@
+CREATE TABLE audit (
+    id INTEGER PRIMARY KEY ASC,
+    task_id INTEGER NOT NULL,
+    owner TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    action TEXT NOT NULL,
+    created DATETIME NOT NULL,
+    changes TEXT NOT NULL
+);
+
+CREATE INDEX audit_owner_task_id ON audit (owner, task_id);
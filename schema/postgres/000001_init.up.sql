@@ -0,0 +1,6 @@
+CREATE TABLE tasks (
+    id BIGSERIAL PRIMARY KEY,
+    created TIMESTAMPTZ,
+    title TEXT,
+    description TEXT
+);
@@ -0,0 +1,3 @@
+DROP INDEX tasks_owner;
+
+ALTER TABLE tasks DROP COLUMN owner;
@@ -0,0 +1,3 @@
+DROP INDEX tasks_owner_idempotency_key;
+
+ALTER TABLE tasks DROP COLUMN idempotency_key;
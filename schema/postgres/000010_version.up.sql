@@ -0,0 +1,3 @@
+-- version is incremented by each update, so clients can detect concurrent
+-- changes.
+ALTER TABLE tasks ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,26 @@
+// Package schema contains the database migrations.
+package schema
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// FS contains the SQLite migrations so they can be shipped inside the binary.
+//
+//go:embed *.sql
+var FS embed.FS
+
+//go:embed postgres/*.sql
+var postgres embed.FS
+
+// Postgres returns the PostgreSQL migrations.
+func Postgres() fs.FS {
+	sub, err := fs.Sub(postgres, "postgres")
+	if err != nil {
+		// only fails if the directory name is invalid
+		panic(err)
+	}
+
+	return sub
+}